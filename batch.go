@@ -0,0 +1,117 @@
+package query
+
+import (
+	"context"
+	"time"
+)
+
+// Deletes the rows matching a condition in batches, so large deletes don't
+// lock the table for a long time. See Builder.BatchDelete.
+type BatchDelete struct {
+	Table string
+
+	builder  *Builder
+	where    Where
+	size     int
+	key      string
+	pause    time.Duration
+	progress func(deleted, total int64)
+}
+
+// Deletes the rows matching where, at most size rows per statement.
+//
+// Each batch selects the rows to delete by their ctid (PostgreSQL) or rowid
+// (SQLite), MySQL uses DELETE ... LIMIT. Use Key to select them by a column
+// instead (e.g. for partitioned tables).
+//
+// Rows are always removed, also from tables that use soft deletes.
+func (b *Builder) BatchDelete(table string, where Where, size int) *BatchDelete {
+	if size <= 0 {
+		panic("Batch size must be positive")
+	}
+	return &BatchDelete{
+		Table:   table,
+		builder: b,
+		where:   where,
+		size:    size,
+	}
+}
+
+// Selects the rows of each batch by the given (unique) column
+func (d *BatchDelete) Key(column string) *BatchDelete {
+	d.key = column
+	return d
+}
+
+// Waits between batches
+func (d *BatchDelete) Pause(pause time.Duration) *BatchDelete {
+	d.pause = pause
+	return d
+}
+
+// Called after each batch with the number of rows it deleted and the total so far
+func (d *BatchDelete) Progress(fn func(deleted, total int64)) *BatchDelete {
+	d.progress = fn
+	return d
+}
+
+// Generates the statement that deletes a single batch
+func (d *BatchDelete) ToSQL() (string, []any) {
+	dialect := d.builder.dialect
+	if dialect.SupportsDeleteLimit() {
+		del := d.builder.Delete(d.Table, d.where).HardDelete().Limit(d.size)
+		if d.key != "" {
+			del.Order(Asc(d.key))
+		}
+		return del.ToSQL()
+	}
+
+	key := d.key
+	if key == "" {
+		key = dialect.RowID()
+	}
+	if key == "" {
+		panic("Dialect has no row identifier, set a Key()")
+	}
+
+	batch := d.builder.Select(key, d.Table).Where(d.where).WithDeleted().Limit(int64(d.size))
+	return d.builder.Delete(d.Table, In(key, batch)).HardDelete().ToSQL()
+}
+
+// Deletes batches until one deletes fewer rows than the batch size. Returns
+// the total number of deleted rows, also when the context is cancelled.
+func (d *BatchDelete) Run(ctx context.Context, db Execer) (int64, error) {
+	query, args := d.ToSQL()
+	total := int64(0)
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		res, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if d.progress != nil {
+			d.progress(n, total)
+		}
+		if n < int64(d.size) {
+			return total, nil
+		}
+
+		if d.pause > 0 {
+			timer := time.NewTimer(d.pause)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return total, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchDelete(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	where := And(FieldLessThan("created", 100), FieldEquals("kind", "log"))
+
+	s, v := NewBuilder(PostgreSQLDialect{}).BatchDelete("events", where, 500).ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE ctid IN (SELECT ctid FROM events WHERE (created<$1 AND kind=$2) LIMIT 500)")
+	assert.Equal(v, []any{100, "log"})
+
+	s, v = NewBuilder(PostgreSQLDialect{}).WithTenant(Tenant{Column: "account", Value: 3}).BatchDelete("events", where, 500).Key("id").ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE id IN (SELECT id FROM events WHERE (created<$1 AND kind=$2) AND events.account=$3 LIMIT 500) AND events.account=$4")
+	assert.Equal(v, []any{100, "log", 3, 3})
+
+	s, v = NewBuilder(SqliteDialect{}).BatchDelete("events", where, 500).ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE rowid IN (SELECT rowid FROM events WHERE (created<? AND kind=?) LIMIT 500)")
+	assert.Equal(v, []any{100, "log"})
+
+	s, v = NewBuilder(MySQLDialect{}).BatchDelete("events", where, 500).ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE (created<? AND kind=?) LIMIT 500")
+	assert.Equal(v, []any{100, "log"})
+
+	s, _ = NewBuilder(MySQLDialect{}).BatchDelete("events", where, 500).Key("id").ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE (created<? AND kind=?) ORDER BY id LIMIT 500")
+
+	// Delete scopes apply on every dialect
+	locked := Scope{
+		Delete: func(d *Delete) {
+			d.Where(FieldEquals("locked", false))
+		},
+	}
+	s, v = NewBuilder(PostgreSQLDialect{}).DefaultScopes("events", locked).BatchDelete("events", where, 500).ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE ctid IN (SELECT ctid FROM events WHERE (created<$1 AND kind=$2) LIMIT 500) AND locked=$3")
+	assert.Equal(v, []any{100, "log", false})
+
+	s, v = NewBuilder(MySQLDialect{}).DefaultScopes("events", locked).BatchDelete("events", where, 500).ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE (created<? AND kind=?) AND locked=? LIMIT 500")
+	assert.Equal(v, []any{100, "log", false})
+
+	// Soft deleted rows are removed too
+	type Event struct {
+		DeletedAt *time.Time `db:"deleted_at,softdelete"`
+	}
+	s, _ = NewBuilder(SqliteDialect{}).Register("events", Event{}).BatchDelete("events", where, 500).ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE rowid IN (SELECT rowid FROM events WHERE (created<? AND kind=?) LIMIT 500)")
+
+	assert.Panics(func() {
+		NewBuilder(MySQLDialect{}).BatchDelete("events", where, 0)
+	})
+}
+
+func TestBatchDeleteRun(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(MySQLDialect{})
+
+	progress := make([][2]int64, 0)
+	db := &fakeExecer{rows: []int64{10, 10, 3}}
+	total, err := b.BatchDelete("events", FieldLessThan("created", 100), 10).
+		Pause(time.Millisecond).
+		Progress(func(deleted, total int64) {
+			progress = append(progress, [2]int64{deleted, total})
+		}).
+		Run(context.Background(), db)
+	assert.NoError(err)
+	assert.Equal(total, int64(23))
+	assert.Len(db.queries, 3)
+	assert.Equal(db.queries[0], "DELETE FROM events WHERE created<? LIMIT 10")
+	assert.Equal(progress, [][2]int64{{10, 10}, {10, 20}, {3, 23}})
+
+	// Stops when the context is cancelled during a pause
+	ctx, cancel := context.WithCancel(context.Background())
+	db = &fakeExecer{rows: []int64{10, 10, 10}}
+	total, err = b.BatchDelete("events", All(), 10).
+		Pause(time.Hour).
+		Progress(func(deleted, total int64) {
+			cancel()
+		}).
+		Run(ctx, db)
+	assert.True(errors.Is(err, context.Canceled))
+	assert.Equal(total, int64(10))
+	assert.Len(db.queries, 1)
+}
@@ -1,7 +1,17 @@
 package query
 
+// Creates statements for a dialect. The builder defaults are applied to a copy
+// of each statement when it is rendered:
+//
+//   - default scopes (see DefaultScopes), unless Unscoped() is called
+//   - the tenant filter or column (see WithTenant)
+//   - soft deletes and timestamps of registered tables (see Register)
 type Builder struct {
 	dialect Dialect
+	scopes  map[string][]Scope
+	tenant  *Tenant
+	models  map[string]*model
+	clock   Clock
 }
 
 func NewBuilder(dialect Dialect) *Builder {
@@ -16,6 +26,11 @@ func (b *Builder) Select(fields, table string, args ...any) *Select {
 		Fields:  fields,
 		Table:   table,
 		Args:    args,
+
+		defaultScopes: b.defaultScopes(table),
+		tenant:        b.tenant,
+		softDelete:    b.model(table).softDelete,
+		models:        b.models,
 	}
 }
 
@@ -25,6 +40,11 @@ func (b *Builder) BulkInsert(table string, columns []string) *BulkInsert {
 		Table:   table,
 		Dialect: b.dialect,
 		Columns: columns,
+
+		defaultScopes: b.defaultScopes(table),
+		tenant:        b.tenant,
+		model:         b.model(table),
+		clock:         b.clock,
 	}
 }
 
@@ -35,6 +55,11 @@ func (b *Builder) BulkUpsert(table string, columns []string, conflictColumn []st
 		Dialect:        b.dialect,
 		Columns:        columns,
 		conflictColumn: conflictColumn,
+
+		defaultScopes: b.defaultScopes(table),
+		tenant:        b.tenant,
+		model:         b.model(table),
+		clock:         b.clock,
 	}
 }
 
@@ -46,6 +71,11 @@ func (b *Builder) Delete(table string, where Where) *Delete {
 	d := &Delete{
 		Table:   table,
 		Dialect: b.dialect,
+
+		defaultScopes: b.defaultScopes(table),
+		tenant:        b.tenant,
+		softDelete:    b.model(table).softDelete,
+		clock:         b.clock,
 	}
 	d.Where(where)
 	return d
@@ -56,6 +86,11 @@ func (b *Builder) Insert(table string) *InsertUpdate {
 		Table:   table,
 		mode:    insertMode,
 		dialect: b.dialect,
+
+		defaultScopes: b.defaultScopes(table),
+		tenant:        b.tenant,
+		model:         b.model(table),
+		clock:         b.clock,
 	}
 }
 
@@ -65,6 +100,11 @@ func (b *Builder) Update(table string, where Where) *InsertUpdate {
 		Table:   table,
 		where:   where,
 		dialect: b.dialect,
+
+		defaultScopes: b.defaultScopes(table),
+		tenant:        b.tenant,
+		model:         b.model(table),
+		clock:         b.clock,
 	}
 }
 
@@ -74,9 +114,21 @@ func (b *Builder) Upsert(table string, conflictColumn ...string) *InsertUpdate {
 		Table:          table,
 		dialect:        b.dialect,
 		conflictColumn: conflictColumn,
+
+		defaultScopes: b.defaultScopes(table),
+		tenant:        b.tenant,
+		model:         b.model(table),
+		clock:         b.clock,
 	}
 }
 
+// Sets the source of the autocreate / autoupdate and soft delete timestamps,
+// defaults to the current time of the application.
+func (b *Builder) SetClock(clock Clock) *Builder {
+	b.clock = clock
+	return b
+}
+
 func (b *Builder) Placeholder(idx int) string {
 	return b.dialect.Placeholder(idx)
 }
@@ -3,6 +3,7 @@ package query
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
@@ -13,46 +14,340 @@ type BulkInsert struct {
 	Dialect        Dialect
 	Values         [][]any
 	conflictColumn []string
+	conflict       *Conflict
+	insertOnly     map[string]bool
+	returning      []string
+	returnInto     reflect.Value
+
+	defaultScopes []Scope
+	unscoped      bool
+	tenant        *Tenant
+	crossTenant   bool
+	model         *model
+	clock         Clock
+}
+
+// Inserts a slice of structs (or pointers to structs), the columns are taken
+// from the db tags and the tag options are handled as in InsertUpdate.With.
+// All elements must have the same type.
+//
+// Empty omitempty and autoincrement fields are inserted as Default. SQLite has
+// no DEFAULT keyword, so an error is returned when only some rows leave such a
+// column empty.
+func (b *Builder) BulkInsertStructs(table string, rows any, opts ...WithOpt) (*BulkInsert, error) {
+	options := &InsertUpdateOptions{}
+	for _, o := range opts {
+		o(options)
+	}
+
+	v := reflect.ValueOf(rows)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Need a slice of structs, got %T", rows)
+	}
+
+	var t reflect.Type
+	var columns []string
+	i := b.BulkInsert(table, nil)
+	now := i.clock.now()
+	for j := 0; j < v.Len(); j++ {
+		e := v.Index(j)
+		for e.Kind() == reflect.Ptr || e.Kind() == reflect.Interface {
+			if e.IsNil() {
+				return nil, fmt.Errorf("Element %d is nil", j)
+			}
+			e = e.Elem()
+		}
+		if e.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("Element %d is a %s, not a struct", j, e.Type())
+		}
+		if t == nil {
+			t = e.Type()
+		} else if e.Type() != t {
+			return nil, fmt.Errorf("Element %d is a %s, expected %s", j, e.Type(), t)
+		}
+
+		c, values := i.structRow(options, e, now)
+		if columns == nil {
+			columns = c
+			i.Columns = c
+		} else if strings.Join(c, ",") != strings.Join(columns, ",") {
+			return nil, fmt.Errorf("Element %d has columns %v, expected %v", j, c, columns)
+		}
+		if err := i.Add(values...); err != nil {
+			return nil, err
+		}
+	}
+	if columns == nil {
+		return nil, errors.New("Need at least one row")
+	}
+	return i, nil
 }
 
+// Adds a row. Dialects without a DEFAULT keyword (SQLite) leave out the
+// columns that are Default, so every row needs Default in the same columns.
 func (i *BulkInsert) Add(values ...any) error {
 	if len(values) != len(i.Columns) {
 		return errors.New("Length mismatch")
 	}
+	if !i.Dialect.SupportsDefault() && len(i.Values) > 0 {
+		for j, value := range values {
+			if isDefault(value) != isDefault(i.Values[0][j]) {
+				return fmt.Errorf("Row %d and the first row differ in using Default for %s, which this dialect can't insert in one statement", len(i.Values), i.Columns[j])
+			}
+		}
+	}
+	if i.model != nil && len(i.model.codecs) > 0 {
+		values = i.encode(values)
+	}
 	i.Values = append(i.Values, values)
 	return nil
 }
 
-func (i *BulkInsert) ToSQL() (string, []any) {
-	switch i.mode {
-	case insertMode:
-		vars := make([]any, 0)
-		placeholders := make([]string, 0)
+// Turns the insert into an upsert with the given conflict handling
+func (i *BulkInsert) OnConflict(conflict *Conflict) *BulkInsert {
+	i.mode = upsertMode
+	i.conflict = conflict
+	return i
+}
+
+// Converts a struct into a row, tag options are handled as in
+// InsertUpdate.With. Zero omitempty and autoincrement fields are inserted as
+// Default, so all rows have the same columns.
+func (i *BulkInsert) structRow(options *InsertUpdateOptions, v reflect.Value, now any) ([]string, []any) {
+	columns := make([]string, 0)
+	values := make([]any, 0)
+	structFields(v.Type(), v, func(f structField) {
+		if f.hasOption("readonly") && !options.CopyReadOnly {
+			return
+		}
+		if options.skip(f) {
+			return
+		}
 
-		for _, row := range i.Values {
-			placeholderVars := make([]string, 0)
-			for n := range i.Columns {
-				placeholderVars = append(placeholderVars, i.Dialect.Placeholder(len(vars)+n))
+		columns = append(columns, f.column)
+		switch {
+		case f.hasOption("autocreate"):
+			values = append(values, now)
+			if i.insertOnly == nil {
+				i.insertOnly = make(map[string]bool)
 			}
-			placeholder := fmt.Sprintf("(%s)", strings.Join(placeholderVars, ", "))
-			placeholders = append(placeholders, placeholder)
-			vars = append(vars, row...)
+			i.insertOnly[f.column] = true
+		case f.hasOption("autoupdate"):
+			values = append(values, now)
+		case f.hasOption("autoincrement") && !options.CopyAutoIncrement && f.value.IsZero():
+			values = append(values, Default)
+		case f.hasOption("omitempty") && f.value.IsZero():
+			values = append(values, Default)
+		default:
+			values = append(values, f.columnValue())
+		}
+	})
+	return columns, values
+}
+
+// Leaves out the columns that are Default in every row
+func (i *BulkInsert) withoutDefaults() ([]string, [][]any) {
+	if len(i.Values) == 0 {
+		return i.Columns, i.Values
+	}
+
+	keep := make([]bool, len(i.Columns))
+	for _, row := range i.Values {
+		for j, value := range row {
+			keep[j] = keep[j] || !isDefault(value)
+		}
+	}
+
+	columns := make([]string, 0)
+	for j, column := range i.Columns {
+		if keep[j] {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		if i.Dialect.SupportsDefault() {
+			return i.Columns, i.Values
+		}
+		panic("Can't insert rows with only default values")
+	}
+	if len(columns) == len(i.Columns) {
+		return i.Columns, i.Values
+	}
+
+	values := make([][]any, len(i.Values))
+	for n, row := range i.Values {
+		for j, value := range row {
+			if keep[j] {
+				values[n] = append(values[n], value)
+			}
+		}
+	}
+	return columns, values
+}
+
+// Encodes the values of columns with a codec in the registered model
+func (i *BulkInsert) encode(values []any) []any {
+	encoded := make([]any, len(values))
+	for j, value := range values {
+		encoded[j] = value
+		c, ok := i.model.codecs[i.Columns[j]]
+		if !ok {
+			continue
 		}
+		if _, ok := c.(textCodec); ok && !isTextMarshaler(value) {
+			continue
+		}
+		switch value.(type) {
+		case nil, SQLValue, Expression, encodedValue:
+		default:
+			encoded[j] = encodedValue{codec: c, value: value}
+		}
+	}
+	return encoded
+}
+
+// Returns the given columns of each inserted row
+func (i *BulkInsert) Returning(columns ...string) *BulkInsert {
+	i.returning = append(i.returning, columns...)
+	return i
+}
+
+// Returns the columns of the struct type in a slice (db tags) and writes the
+// returned rows back into its elements when using Scan, one row per inserted
+// row in order. Rows skipped by an upsert (DO NOTHING) break this ordering.
+func (i *BulkInsert) ReturningStruct(slice any) *BulkInsert {
+	v := reflect.ValueOf(slice)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("ReturningStruct() needs a slice, got %s", v.Type()))
+	}
+	t := v.Type().Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("ReturningStruct() needs a slice of structs, got %s", v.Type()))
+	}
+	i.returnInto = v
+	return i.Returning(structColumns(t)...)
+}
 
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", i.Table, strings.Join(i.Columns, ", "), strings.Join(placeholders, ", "))
+// Applies the given scopes to the statement
+func (i *BulkInsert) Scopes(scopes ...Scope) *BulkInsert {
+	for _, scope := range scopes {
+		if scope.BulkInsert != nil {
+			scope.BulkInsert(i)
+		}
+	}
+	return i
+}
+
+// Skips the default scopes registered on the builder for this table
+func (i *BulkInsert) Unscoped() *BulkInsert {
+	i.unscoped = true
+	return i
+}
+
+// Allows an upsert to overwrite rows of other tenants on dialects that can't
+// restrict the update to the tenant (MySQL), which otherwise panic
+func (i *BulkInsert) AllowCrossTenantUpsert() *BulkInsert {
+	i.crossTenant = true
+	return i
+}
+
+// Whether scopes, the tenant or timestamp columns still need to be added to
+// the rows, see Builder
+func (i *BulkInsert) hasDefaults() bool {
+	return (len(i.defaultScopes) > 0 && !i.unscoped) || i.tenant != nil || i.hasTimestamps()
+}
+
+// Applies the builder defaults, only use this on a clone
+func (i *BulkInsert) applyDefaults() {
+	if !i.unscoped {
+		i.Scopes(i.defaultScopes...)
+	}
+	if i.tenant != nil {
+		i.applyTenant(i.tenant)
+	}
+	if i.hasTimestamps() {
+		i.applyTimestamps()
+	}
+	i.defaultScopes = nil
+	i.tenant = nil
+	i.model = nil
+}
+
+// Returns a deep copy of the statement, which can be modified independently
+func (i *BulkInsert) Clone() *BulkInsert {
+	c := *i
+	c.Columns = append([]string(nil), i.Columns...)
+	if i.Values != nil {
+		c.Values = make([][]any, len(i.Values))
+		for j, row := range i.Values {
+			c.Values[j] = cloneArgs(row)
+		}
+	}
+	c.conflictColumn = append([]string(nil), i.conflictColumn...)
+	c.returning = append([]string(nil), i.returning...)
+	if i.conflict != nil {
+		c.conflict = i.conflict.clone()
+	}
+	if i.insertOnly != nil {
+		c.insertOnly = make(map[string]bool)
+		for k, v := range i.insertOnly {
+			c.insertOnly[k] = v
+		}
+	}
+	return &c
+}
+
+func (i *BulkInsert) ToSQL() (string, []any) {
+	if i.hasDefaults() {
+		c := i.Clone()
+		c.applyDefaults()
+		return c.ToSQL()
+	}
+
+	query, vars := i.insertSQL()
+	if len(i.returning) > 0 {
+		query = fmt.Sprintf("%s RETURNING %s", query, strings.Join(i.returning, ", "))
+	}
+	return query, vars
+}
+
+func (i *BulkInsert) insertSQL() (string, []any) {
+	columns, values := i.withoutDefaults()
+
+	vars := make([]any, 0)
+	rows := make([]string, 0)
+	for _, values := range values {
+		row, v := generateRow(values, len(vars), i.Dialect)
+		rows = append(rows, row)
+		vars = append(vars, v...)
+	}
+
+	switch i.mode {
+	case insertMode:
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", i.Table, strings.Join(columns, ", "), strings.Join(rows, ", "))
 		return query, vars
 	case upsertMode:
-		vars := make([]any, 0)
-		for _, row := range i.Values {
-			vars = append(vars, row...)
-		}
 		fvs := make([]fieldValue, 0)
-		for _, column := range i.Columns {
+		for _, column := range columns {
 			fvs = append(fvs, fieldValue{
-				key: column,
+				key:        column,
+				insertOnly: i.insertOnly[column],
 			})
 		}
-		query := i.Dialect.MakeUpsert(i.Table, i.conflictColumn, fvs, len(i.Values))
+		if i.conflict != nil {
+			q, v := i.conflict.generate(i.Table, fvs, fmt.Sprintf("VALUES %s", strings.Join(rows, ", ")), len(vars), i.Dialect)
+			return q, append(vars, v...)
+		}
+		query := i.Dialect.MakeUpsert(i.Table, i.conflictColumn, fvs, rows)
 		return query, vars
 	default:
 		panic(fmt.Sprintf("Unknown mode: %#v", i.mode))
@@ -2,6 +2,7 @@ package query
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -67,3 +68,117 @@ func TestBulkUpsert(t *testing.T) {
 	assert.Equal(args[7], "Test 3")
 	assert.Equal(args[8], "FR")
 }
+
+func TestBulkInsertDefault(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	insert := NewBuilder(PostgreSQLDialect{}).BulkInsert("events", []string{"kind", "name"})
+	assert.NoError(insert.Add(Default, "a"))
+	assert.NoError(insert.Add(2, "b"))
+	s, v := insert.ToSQL()
+	assert.Equal(s, "INSERT INTO events (kind, name) VALUES (DEFAULT, $1), ($2, $3)")
+	assert.Equal(v, []any{"a", 2, "b"})
+
+	sqlite := NewBuilder(SqliteDialect{})
+	insert = sqlite.BulkInsert("events", []string{"kind", "name"})
+	assert.NoError(insert.Add(Default, "a"))
+	assert.NoError(insert.Add(Default, "b"))
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO events (name) VALUES (?), (?)")
+	assert.Equal(v, []any{"a", "b"})
+
+	// Mixing values and Default in a column can't be done in one statement
+	insert = sqlite.BulkInsert("events", []string{"kind", "name"})
+	assert.NoError(insert.Add(Default, "a"))
+	assert.Error(insert.Add(2, "b"))
+	assert.Error(insert.Add(Default, Default))
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO events (name) VALUES (?)")
+	assert.Equal(v, []any{"a"})
+}
+
+func TestBulkInsertStructs(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Event struct {
+		ID      int64             `db:"id,autoincrement"`
+		Name    string            `db:"name"`
+		Kind    int               `db:"kind,omitempty"`
+		Meta    map[string]string `db:"meta,json"`
+		Secret  string            `db:"secret,readonly"`
+		Created time.Time         `db:"created,autocreate"`
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuilder(PostgreSQLDialect{}).SetClock(func() any {
+		return now
+	})
+
+	insert, err := b.BulkInsertStructs("events", []Event{
+		{Name: "a", Kind: 2},
+		{Name: "b"},
+	}, WithExclude("meta"))
+	assert.NoError(err)
+	s, v := insert.ToSQL()
+	assert.Equal(s, "INSERT INTO events (name, kind, created) VALUES ($1, $2, $3), ($4, DEFAULT, $5)")
+	assert.Equal(v, []any{"a", 2, now, "b", now})
+
+	// Non-zero autoincrement fields are kept, as in With
+	insert, err = b.BulkInsertStructs("events", []Event{
+		{ID: 1, Name: "a"},
+		{Name: "b"},
+	}, WithOnly("id", "name"))
+	assert.NoError(err)
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO events (id, name, created) VALUES ($1, $2, $3), (DEFAULT, $4, $5)")
+	assert.Equal(v, []any{int64(1), "a", now, "b", now})
+
+	// SQLite leaves out empty columns, which only works if all rows agree
+	sqlite := NewBuilder(SqliteDialect{}).SetClock(func() any {
+		return now
+	})
+	insert, err = sqlite.BulkInsertStructs("events", []Event{
+		{Name: "a"},
+		{Name: "b"},
+	}, WithOnly("id", "name", "kind"))
+	assert.NoError(err)
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO events (name, created) VALUES (?, ?), (?, ?)")
+	assert.Equal(v, []any{"a", now, "b", now})
+
+	_, err = sqlite.BulkInsertStructs("events", []Event{
+		{Name: "a", Kind: 2},
+		{Name: "b"},
+	}, WithOnly("name", "kind"))
+	assert.Error(err)
+
+	_, err = sqlite.BulkInsertStructs("events", []Event{
+		{ID: 1, Name: "a"},
+		{Name: "b"},
+	}, WithOnly("id", "name"))
+	assert.Error(err)
+
+	insert, err = b.BulkInsertStructs("events", []*Event{{ID: 1, Name: "a"}}, WithAutoIncrement(), WithOnly("id", "name"))
+	assert.NoError(err)
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO events (id, name, created) VALUES ($1, $2, $3)")
+	assert.Equal(v, []any{int64(1), "a", now})
+
+	_, err = b.BulkInsertStructs("events", []any{Event{Name: "a"}, struct {
+		Name string `db:"name"`
+	}{"b"}})
+	assert.Error(err)
+
+	_, err = b.BulkInsertStructs("events", []*Event{{Name: "a"}, nil})
+	assert.Error(err)
+
+	_, err = b.BulkInsertStructs("events", []Event{})
+	assert.Error(err)
+
+	_, err = b.BulkInsertStructs("events", Event{})
+	assert.Error(err)
+}
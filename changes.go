@@ -0,0 +1,171 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// The column values of a struct at some point in time (e.g. when it was
+// loaded), used to find the columns that changed since, see WithChanges.
+type Snapshot struct {
+	columns []string
+	values  map[string]any
+}
+
+// Records the current column values of a struct (db tags)
+func TakeSnapshot(obj any) Snapshot {
+	v := reflect.ValueOf(obj)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("TakeSnapshot() needs a struct, got %T", obj))
+	}
+
+	// Columns of nil struct pointers are recorded as nil
+	s := Snapshot{
+		values: make(map[string]any),
+	}
+	typeFields(v.Type(), func(f structField) {
+		s.columns = append(s.columns, f.column)
+		s.values[f.column] = nil
+	})
+	structFields(v.Type(), v, func(f structField) {
+		s.values[f.column] = snapshotValue(f.value)
+	})
+	return s
+}
+
+// Deep copies the value, so modifying it in place (e.g. a map key or the target
+// of a pointer) is seen as a change
+func snapshotValue(v reflect.Value) any {
+	return deepCopy(v, make(map[uintptr]reflect.Value)).Interface()
+}
+
+// Copies maps, pointers, slices, arrays, interfaces and the exported fields of
+// structs. Pointers already seen are reused, which preserves cycles.
+func deepCopy(v reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if c, ok := seen[v.Pointer()]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		seen[v.Pointer()] = c
+		c.Elem().Set(deepCopy(v.Elem(), seen))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(deepCopy(iter.Key(), seen), deepCopy(iter.Value(), seen))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for j := 0; j < v.Len(); j++ {
+			c.Index(j).Set(deepCopy(v.Index(j), seen))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for j := 0; j < v.Len(); j++ {
+			c.Index(j).Set(deepCopy(v.Index(j), seen))
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), seen))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for j := 0; j < v.NumField(); j++ {
+			if c.Field(j).CanSet() {
+				c.Field(j).Set(deepCopy(v.Field(j), seen))
+			}
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// Returns the columns of obj that differ from the snapshot
+func (s Snapshot) Changed(obj any) []string {
+	current := TakeSnapshot(obj)
+	changed := make([]string, 0)
+	for _, column := range current.columns {
+		old, ok := s.values[column]
+		if !ok || !equalValues(old, current.values[column]) {
+			changed = append(changed, column)
+		}
+	}
+	return changed
+}
+
+func equalValues(a, b any) bool {
+	if t, ok := a.(time.Time); ok {
+		if u, ok := b.(time.Time); ok {
+			return t.Equal(u)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Adds the fields of modified that differ from original, which is either a
+// copy of the struct as it was loaded or a Snapshot of it. Tag options are
+// handled as in With, the autoupdate and version columns are only set when
+// something changed. Changed omitempty fields are also set when they're empty.
+//
+// Exec returns ErrNoChanges without running a statement when nothing changed.
+func (i *InsertUpdate) WithChanges(original, modified any, opts ...WithOpt) *InsertUpdate {
+	if i.mode != updateMode {
+		panic("WithChanges() can only be used in updates")
+	}
+
+	snapshot, ok := original.(Snapshot)
+	if !ok {
+		snapshot = TakeSnapshot(original)
+	}
+
+	options := &InsertUpdateOptions{}
+	for _, o := range opts {
+		o(options)
+	}
+	changed := make([]string, 0)
+	for _, column := range snapshot.Changed(modified) {
+		if !options.skip(structField{column: column}) {
+			changed = append(changed, column)
+		}
+	}
+
+	i.onlyChanges = true
+	if len(changed) == 0 {
+		return i
+	}
+
+	opts = append(append([]WithOpt(nil), opts...), func(o *InsertUpdateOptions) {
+		o.Only = changed
+		o.changes = true
+	})
+	return i.With(modified, opts...)
+}
+
+// Whether this is a WithChanges update without anything to update
+func (i *InsertUpdate) isUnchanged() bool {
+	return i.onlyChanges && len(i.fields) == 0
+}
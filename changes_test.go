@@ -0,0 +1,168 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithChanges(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Document struct {
+		ID        int64     `db:"id,autoincrement"`
+		Title     string    `db:"title"`
+		Body      string    `db:"body"`
+		Tags      []byte    `db:"tags"`
+		Published time.Time `db:"published"`
+		UpdatedAt time.Time `db:"updated_at,autoupdate"`
+		Version   int32     `db:"version,version"`
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuilder(PostgreSQLDialect{}).SetClock(func() any {
+		return now
+	})
+
+	doc := &Document{ID: 3, Title: "Hello", Body: "World", Tags: []byte("a"), Published: now, Version: 2}
+	original := *doc
+	snapshot := TakeSnapshot(doc)
+
+	doc.Title = "Hi"
+	doc.Tags[0] = 'b'
+	doc.Published = now.In(time.FixedZone("CEST", 2*60*60))
+	assert.Equal(snapshot.Changed(doc), []string{"title", "tags"})
+
+	s, v := b.Update("documents", IDEquals(3)).WithChanges(snapshot, doc).ToSQL()
+	assert.Equal(s, "UPDATE documents SET title=$1, tags=$2, updated_at=$3, version=$4 WHERE id=$5 AND documents.version=$6")
+	assert.Equal(v, []any{"Hi", []byte("b"), now, int32(3), 3, int32(2)})
+
+	doc = &Document{ID: 3, Title: "Hello", Body: "Changed", Version: 2}
+	s, v = b.Update("documents", IDEquals(3)).WithChanges(Document{ID: 3, Title: "Hello", Version: 2}, doc).ToSQL()
+	assert.Equal(s, "UPDATE documents SET body=$1, updated_at=$2, version=$3 WHERE id=$4 AND documents.version=$5")
+	assert.Equal(v, []any{"Changed", now, int32(3), 3, int32(2)})
+
+	// Nothing changed: no statement is executed
+	db := &fakeExecer{}
+	_, err := b.Update("documents", IDEquals(3)).WithChanges(&original, &original).Exec(context.Background(), db)
+	assert.True(errors.Is(err, ErrNoChanges))
+	assert.Len(db.queries, 0)
+
+	// Unless other fields are added
+	db = &fakeExecer{rows: []int64{1}}
+	_, err = b.Update("documents", IDEquals(3)).WithChanges(&original, &original).Add("body", "x").Exec(context.Background(), db)
+	assert.NoError(err)
+	assert.Equal(db.queries, []string{"UPDATE documents SET body=$1 WHERE id=$2"})
+
+	assert.Panics(func() {
+		b.Insert("documents").WithChanges(original, doc)
+	})
+}
+
+func TestWithChangesOnly(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Document struct {
+		ID    int64  `db:"id"`
+		Title string `db:"title"`
+		Body  string `db:"body"`
+	}
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	original := Document{ID: 3, Title: "Hello", Body: "World"}
+	doc := Document{ID: 3, Title: "Hi", Body: "There"}
+	s, v := b.Update("documents", IDEquals(3)).WithChanges(original, doc, WithOnly("id", "body")).ToSQL()
+	assert.Equal(s, "UPDATE documents SET body=$1 WHERE id=$2")
+	assert.Equal(v, []any{"There", 3})
+}
+
+func TestWithChangesOmitEmpty(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Document struct {
+		ID    int64  `db:"id"`
+		Title string `db:"title,omitempty"`
+		Body  string `db:"body,omitempty"`
+	}
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	// Clearing an omitempty field is a change
+	original := Document{ID: 3, Title: "Hello", Body: "World"}
+	doc := Document{ID: 3, Body: "There"}
+	s, v := b.Update("documents", IDEquals(3)).WithChanges(original, doc).ToSQL()
+	assert.Equal(s, "UPDATE documents SET title=$1, body=$2 WHERE id=$3")
+	assert.Equal(v, []any{"", "There", 3})
+}
+
+func TestWithChangesNested(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Address struct {
+		City string `db:"city"`
+	}
+	type Customer struct {
+		ID      int64    `db:"id"`
+		Address *Address `db:"address_,prefix"`
+	}
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	// Columns of a nil pointer are part of the snapshot
+	customer := &Customer{ID: 3}
+	snapshot := TakeSnapshot(customer)
+	customer.Address = &Address{City: "Ghent"}
+	assert.Equal(snapshot.Changed(customer), []string{"address_city"})
+
+	s, v := b.Update("customers", IDEquals(3)).WithChanges(snapshot, customer).ToSQL()
+	assert.Equal(s, "UPDATE customers SET address_city=$1 WHERE id=$2")
+	assert.Equal(v, []any{"Ghent", 3})
+}
+
+func TestSnapshotDeep(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Settings struct {
+		Theme string
+	}
+	type Profile struct {
+		ID       int64             `db:"id"`
+		Meta     map[string]string `db:"meta,json"`
+		Settings *Settings         `db:"settings,json"`
+		Scores   [][]int           `db:"scores,json"`
+	}
+
+	profile := &Profile{
+		ID:       3,
+		Meta:     map[string]string{"lang": "en"},
+		Settings: &Settings{Theme: "dark"},
+		Scores:   [][]int{{1, 2}},
+	}
+	snapshot := TakeSnapshot(profile)
+	assert.Len(snapshot.Changed(profile), 0)
+
+	profile.Meta["lang"] = "nl"
+	assert.Equal(snapshot.Changed(profile), []string{"meta"})
+
+	profile.Meta["lang"] = "en"
+	profile.Settings.Theme = "light"
+	assert.Equal(snapshot.Changed(profile), []string{"settings"})
+
+	profile.Settings.Theme = "dark"
+	profile.Scores[0][1] = 3
+	assert.Equal(snapshot.Changed(profile), []string{"scores"})
+}
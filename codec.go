@@ -0,0 +1,226 @@
+package query
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Converts a field to a column value and back, selected through a tag option
+// (e.g. `db:"meta,json"`). See RegisterCodec.
+type Codec interface {
+	Encode(value any) (driver.Value, error)
+
+	// Decodes a column value into dest, a pointer to the field
+	Decode(src any, dest any) error
+}
+
+var (
+	codecsMutex sync.RWMutex
+	codecs      = map[string]Codec{
+		"json":   jsonCodec{},
+		"text":   textCodec{},
+		"gob":    gobCodec{},
+		"binary": binaryCodec{},
+	}
+)
+
+// Tag options that can't be used as codec names
+var reservedOptions = map[string]bool{
+	"autoincrement": true,
+	"readonly":      true,
+	"version":       true,
+	"autocreate":    true,
+	"autoupdate":    true,
+	"softdelete":    true,
+	"omitempty":     true,
+	"prefix":        true,
+}
+
+// Registers a codec under a tag option name, replacing any existing codec
+// with that name.
+func RegisterCodec(name string, codec Codec) {
+	if reservedOptions[name] {
+		panic(fmt.Sprintf("Can't use tag option %s as codec name", name))
+	}
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+	codecs[name] = codec
+}
+
+// Returns the codec selected by the tag options of a field, if any
+func (f structField) codec() Codec {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	for _, o := range f.options {
+		if c, ok := codecs[o]; ok {
+			return c
+		}
+	}
+	return nil
+}
+
+// The value of the field as passed to the database
+func (f structField) columnValue() any {
+	value := f.value.Interface()
+	if c := f.codec(); c != nil {
+		if _, ok := c.(textCodec); ok && !isTextMarshaler(value) {
+			return value
+		}
+		return encodedValue{codec: c, value: value}
+	}
+	return value
+}
+
+// The destination to scan the column of the field into
+func (f structField) scanTarget() any {
+	addr := f.value.Addr().Interface()
+	if c := f.codec(); c != nil {
+		if _, ok := c.(textCodec); ok {
+			if _, ok := addr.(encoding.TextUnmarshaler); !ok {
+				return addr
+			}
+		}
+		return decodedValue{codec: c, dest: f.value}
+	}
+	return addr
+}
+
+// A field value that is encoded by a codec when it is sent to the database
+type encodedValue struct {
+	codec Codec
+	value any
+}
+
+// Nil pointers, maps and slices are stored as NULL
+func (v encodedValue) Value() (driver.Value, error) {
+	r := reflect.ValueOf(v.value)
+	switch r.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		if r.IsNil() {
+			return nil, nil
+		}
+	}
+	return v.codec.Encode(v.value)
+}
+
+// Decodes a column into a field
+type decodedValue struct {
+	codec Codec
+	dest  reflect.Value
+}
+
+// The field is reset first, so decoding replaces its value instead of merging
+// into it (e.g. for maps)
+func (v decodedValue) Scan(src any) error {
+	v.dest.Set(reflect.Zero(v.dest.Type()))
+	if src == nil {
+		return nil
+	}
+	return v.codec.Decode(src, v.dest.Addr().Interface())
+}
+
+func sourceBytes(src any) ([]byte, error) {
+	switch s := src.(type) {
+	case []byte:
+		return s, nil
+	case string:
+		return []byte(s), nil
+	default:
+		return nil, fmt.Errorf("Can't decode %T", src)
+	}
+}
+
+type jsonCodec struct{}
+
+func (c jsonCodec) Encode(value any) (driver.Value, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c jsonCodec) Decode(src any, dest any) error {
+	data, err := sourceBytes(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// Uses encoding.TextMarshaler, other values are passed unchanged
+type textCodec struct{}
+
+func isTextMarshaler(value any) bool {
+	_, ok := value.(encoding.TextMarshaler)
+	return ok
+}
+
+func (c textCodec) Encode(value any) (driver.Value, error) {
+	data, err := value.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (c textCodec) Decode(src any, dest any) error {
+	u, ok := dest.(encoding.TextUnmarshaler)
+	if !ok {
+		return fmt.Errorf("%T does not implement encoding.TextUnmarshaler", dest)
+	}
+	data, err := sourceBytes(src)
+	if err != nil {
+		return err
+	}
+	return u.UnmarshalText(data)
+}
+
+type gobCodec struct{}
+
+func (c gobCodec) Encode(value any) (driver.Value, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c gobCodec) Decode(src any, dest any) error {
+	data, err := sourceBytes(src)
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(dest)
+}
+
+// Uses encoding.BinaryMarshaler
+type binaryCodec struct{}
+
+func (c binaryCodec) Encode(value any) (driver.Value, error) {
+	m, ok := value.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement encoding.BinaryMarshaler", value)
+	}
+	return m.MarshalBinary()
+}
+
+func (c binaryCodec) Decode(src any, dest any) error {
+	u, ok := dest.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("%T does not implement encoding.BinaryUnmarshaler", dest)
+	}
+	data, err := sourceBytes(src)
+	if err != nil {
+		return err
+	}
+	return u.UnmarshalBinary(data)
+}
@@ -0,0 +1,133 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type upperCodec struct{}
+
+func init() {
+	RegisterCodec("upper", upperCodec{})
+}
+
+func (c upperCodec) Encode(value any) (driver.Value, error) {
+	return strings.ToUpper(value.(string)), nil
+}
+
+func (c upperCodec) Decode(src any, dest any) error {
+	*dest.(*string) = strings.ToLower(string(src.([]byte)))
+	return nil
+}
+
+func TestCodecs(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Point struct {
+		X, Y int
+	}
+	type Device struct {
+		ID     int64             `db:"id"`
+		Meta   map[string]string `db:"meta,json"`
+		Tags   []string          `db:"tags,json"`
+		IP     net.IP            `db:"ip,text"`
+		Name   string            `db:"name,text"`
+		Origin Point             `db:"origin,gob"`
+		Code   string            `db:"code,upper"`
+	}
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	device := &Device{
+		ID:     1,
+		Meta:   map[string]string{"os": "linux"},
+		IP:     net.ParseIP("10.0.0.1"),
+		Name:   "router",
+		Origin: Point{X: 1, Y: 2},
+		Code:   "ab",
+	}
+	s, v := b.Insert("devices").With(device).ToSQL()
+	assert.Equal(s, "INSERT INTO devices (id, meta, tags, ip, name, origin, code) VALUES ($1, $2, $3, $4, $5, $6, $7)")
+	assert.Len(v, 7)
+	assert.Equal(v[4], "router")
+
+	values := make([]driver.Value, 0)
+	for _, value := range v {
+		if valuer, ok := value.(driver.Valuer); ok {
+			value, err := valuer.Value()
+			assert.NoError(err)
+			values = append(values, value)
+		} else {
+			values = append(values, value)
+		}
+	}
+	assert.Equal(values[1], `{"os":"linux"}`)
+	assert.Nil(values[2])
+	assert.Equal(values[3], "10.0.0.1")
+	assert.IsType(values[5], []byte{})
+	assert.Equal(values[6], "AB")
+
+	// Scanning decodes
+	fake := &fakeRows{
+		columns: []string{"id", "meta", "tags", "ip", "name", "origin", "code"},
+		rows:    [][]driver.Value{{int64(1), []byte(`{"os":"bsd"}`), nil, "10.0.0.2", "switch", values[5], []byte("CD")}},
+	}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	result := &Device{
+		Meta: map[string]string{"os": "linux", "arch": "arm"},
+		Tags: []string{"old"},
+	}
+	rows, err := db.QueryContext(context.Background(), "SELECT")
+	assert.NoError(err)
+	defer rows.Close()
+	assert.True(rows.Next())
+	assert.NoError(ScanStruct(rows, result))
+	assert.Equal(result, &Device{
+		ID:     1,
+		Meta:   map[string]string{"os": "bsd"},
+		IP:     net.ParseIP("10.0.0.2"),
+		Name:   "switch",
+		Origin: Point{X: 1, Y: 2},
+		Code:   "cd",
+	})
+
+	assert.Panics(func() {
+		RegisterCodec("readonly", upperCodec{})
+	})
+}
+
+func TestBulkInsertCodecs(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Device struct {
+		Name string            `db:"name"`
+		Meta map[string]string `db:"meta,json"`
+	}
+
+	b := NewBuilder(PostgreSQLDialect{}).Register("devices", Device{})
+
+	insert := b.BulkInsert("devices", []string{"name", "meta"})
+	assert.NoError(insert.Add("a", map[string]string{"os": "linux"}))
+	assert.NoError(insert.Add("b", nil))
+	assert.NoError(insert.Add("c", Raw("'{}'")))
+	s, v := insert.ToSQL()
+	assert.Equal(s, "INSERT INTO devices (name, meta) VALUES ($1, $2), ($3, $4), ($5, '{}')")
+	assert.Len(v, 5)
+
+	meta, err := v[1].(driver.Valuer).Value()
+	assert.NoError(err)
+	assert.Equal(meta, `{"os":"linux"}`)
+	assert.Nil(v[3])
+}
@@ -0,0 +1,176 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Configures what an upsert does when a row already exists, see OnConflict
+// and OnConstraint.
+//
+// By default all inserted columns are updated with their new value (except
+// autocreate timestamps).
+type Conflict struct {
+	columns     []string
+	constraint  string
+	targetWhere Where
+
+	exclude   []string
+	set       []fieldValue
+	where     Where
+	doNothing bool
+}
+
+// Conflicts on a unique index over the given columns
+func OnConflict(columns ...string) *Conflict {
+	return &Conflict{
+		columns: columns,
+	}
+}
+
+// Conflicts on the named constraint (PostgreSQL)
+func OnConstraint(name string) *Conflict {
+	return &Conflict{
+		constraint: name,
+	}
+}
+
+// Sets the predicate of a partial unique index used as conflict target
+func (c *Conflict) TargetWhere(where Where) *Conflict {
+	c.targetWhere = andWhere(c.targetWhere, where)
+	return c
+}
+
+// Doesn't update the given columns
+func (c *Conflict) Exclude(columns ...string) *Conflict {
+	c.exclude = append(c.exclude, columns...)
+	return c
+}
+
+// Updates a column with the given value instead, use Excluded to refer to
+// the value that was to be inserted:
+//
+//	OnConflict("day").Set("hits", Col("stats.hits").Plus(Excluded("hits")))
+func (c *Conflict) Set(column string, value any) *Conflict {
+	c.set = append(c.set, fieldValue{key: column, value: value})
+	return c
+}
+
+// Only updates the existing row when it matches (PostgreSQL and SQLite)
+func (c *Conflict) Where(where Where) *Conflict {
+	c.where = andWhere(c.where, where)
+	return c
+}
+
+// Leaves the existing row untouched
+func (c *Conflict) DoNothing() *Conflict {
+	c.doNothing = true
+	return c
+}
+
+func (c *Conflict) clone() *Conflict {
+	n := *c
+	n.columns = append([]string(nil), c.columns...)
+	n.targetWhere = c.targetWhere.Clone()
+	n.exclude = append([]string(nil), c.exclude...)
+	n.set = append([]fieldValue(nil), c.set...)
+	n.where = c.where.Clone()
+	return &n
+}
+
+// The value that was to be inserted into the column (EXCLUDED.column)
+func Excluded(column string) Expression {
+	return Expression{
+		mode: excludedExpression,
+		name: column,
+	}
+}
+
+// The rendered parts of an INSERT ... ON CONFLICT statement
+type conflictUpsert struct {
+	table       string
+	columns     []string
+	source      string
+	target      []string
+	constraint  string
+	targetWhere string
+	updates     []string
+	where       string
+}
+
+// Renders the upsert, source is either VALUES or a SELECT and its parameters
+// come first.
+func (c *Conflict) generate(table string, fields []fieldValue, source string, offset int, dialect Dialect) (string, []any) {
+	u := conflictUpsert{
+		table:      table,
+		source:     source,
+		target:     c.columns,
+		constraint: c.constraint,
+	}
+	vars := make([]any, 0)
+
+	q, v := c.targetWhere.Generate(offset+len(vars), dialect)
+	u.targetWhere = q
+	vars = append(vars, v...)
+
+	if !c.doNothing {
+		excluded := make(map[string]bool)
+		for _, column := range c.exclude {
+			excluded[column] = true
+		}
+		for _, field := range c.set {
+			excluded[field.key] = true
+		}
+		for _, field := range fields {
+			if !excluded[field.key] && !field.insertOnly {
+				u.updates = append(u.updates, fmt.Sprintf("%s=%s", field.key, dialect.Excluded(field.key)))
+			}
+		}
+		for _, field := range c.set {
+			q, v := field.generate(offset+len(vars), dialect)
+			u.updates = append(u.updates, fmt.Sprintf("%s=%s", field.key, q))
+			vars = append(vars, v...)
+		}
+
+		q, v := c.where.Generate(offset+len(vars), dialect)
+		u.where = q
+		vars = append(vars, v...)
+	}
+
+	for _, field := range fields {
+		u.columns = append(u.columns, field.key)
+	}
+	return dialect.MakeConflictUpsert(u), vars
+}
+
+func postgreSQLConflictUpsert(u conflictUpsert) string {
+	target := ""
+	if u.constraint != "" {
+		target = fmt.Sprintf(" ON CONSTRAINT %s", u.constraint)
+	} else if len(u.target) > 0 {
+		target = fmt.Sprintf(" (%s)", strings.Join(u.target, ", "))
+	}
+	if u.targetWhere != "" {
+		target = fmt.Sprintf("%s WHERE %s", target, u.targetWhere)
+	}
+
+	action := "NOTHING"
+	if len(u.updates) > 0 {
+		if target == "" {
+			panic("An upsert that updates needs a conflict target")
+		}
+		action = fmt.Sprintf("UPDATE SET %s", strings.Join(u.updates, ", "))
+		if u.where != "" {
+			action = fmt.Sprintf("%s WHERE %s", action, u.where)
+		}
+	}
+	return fmt.Sprintf("INSERT %s ON CONFLICT%s DO %s", insertInto(u.table, u.columns, u.source), target, action)
+}
+
+// Renders "INTO table (columns) source", without the column list if empty
+func insertInto(table string, columns []string, source string) string {
+	if len(columns) == 0 {
+		return fmt.Sprintf("INTO %s %s", table, source)
+	}
+	return fmt.Sprintf("INTO %s (%s) %s", table, strings.Join(columns, ", "), source)
+}
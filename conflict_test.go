@@ -0,0 +1,98 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflict(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, v := b.Insert("stats").
+		Add("day", "2024-05-01").
+		Add("hits", 3).
+		Add("created_at", Now()).
+		OnConflict(OnConflict("day").
+			Exclude("created_at").
+			Set("hits", Col("stats.hits").Plus(Excluded("hits"))).
+			Set("label", Func("concat", Excluded("label"), "!")).
+			Where(FieldLessThan("stats.hits", 1000))).
+		ToSQL()
+	assert.Equal(s, "INSERT INTO stats (day, hits, created_at) VALUES ($1, $2, CURRENT_TIMESTAMP) ON CONFLICT (day) DO UPDATE SET day=EXCLUDED.day, hits=(stats.hits + EXCLUDED.hits), label=concat(EXCLUDED.label, $3) WHERE stats.hits<$4")
+	assert.Equal(v, []any{"2024-05-01", 3, "!", 1000})
+
+	s, v = b.Upsert("users").Add("email", "a@b.c").Add("name", "A").
+		OnConflict(OnConflict("email").TargetWhere(IsNull("deleted_at")).TargetWhere(FieldEquals("kind", 1))).
+		ToSQL()
+	assert.Equal(s, "INSERT INTO users (email, name) VALUES ($1, $2) ON CONFLICT (email) WHERE deleted_at IS NULL AND kind=$3 DO UPDATE SET email=EXCLUDED.email, name=EXCLUDED.name")
+	assert.Equal(v, []any{"a@b.c", "A", 1})
+
+	s, _ = b.Insert("users").Add("email", "a@b.c").OnConflict(OnConstraint("users_email_key").DoNothing()).ToSQL()
+	assert.Equal(s, "INSERT INTO users (email) VALUES ($1) ON CONFLICT ON CONSTRAINT users_email_key DO NOTHING")
+
+	insert := b.BulkInsert("stats", []string{"day", "hits"}).
+		OnConflict(OnConflict("day").Set("hits", Col("stats.hits").Plus(Excluded("hits"))).Where(FieldNotEquals("stats.locked", true)))
+	assert.NoError(insert.Add("2024-05-01", 3))
+	assert.NoError(insert.Add("2024-05-02", 4))
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO stats (day, hits) VALUES ($1, $2), ($3, $4) ON CONFLICT (day) DO UPDATE SET day=EXCLUDED.day, hits=(stats.hits + EXCLUDED.hits) WHERE stats.locked!=$5")
+	assert.Equal(v, []any{"2024-05-01", 3, "2024-05-02", 4, true})
+
+	assert.Panics(func() {
+		b.Insert("users").Add("email", "a@b.c").OnConflict(OnConflict()).ToSQL()
+	})
+	assert.Panics(func() {
+		b.Update("users", All()).OnConflict(OnConflict("id"))
+	})
+}
+
+func TestConflictTimestamps(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Post struct {
+		Slug      string    `db:"slug"`
+		Title     string    `db:"title"`
+		CreatedAt time.Time `db:"created_at,autocreate"`
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuilder(SqliteDialect{}).SetClock(func() any {
+		return now
+	})
+
+	s, v := b.Insert("posts").With(Post{Slug: "hello", Title: "Hello"}).OnConflict(OnConflict("slug").Exclude("slug")).ToSQL()
+	assert.Equal(s, "INSERT INTO posts (slug, title, created_at) VALUES (?, ?, ?) ON CONFLICT (slug) DO UPDATE SET title=EXCLUDED.title")
+	assert.Equal(v, []any{"hello", "Hello", now})
+}
+
+func TestConflictMySQL(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(MySQLDialect{})
+
+	s, v := b.Insert("stats").Add("day", "2024-05-01").Add("hits", 3).
+		OnConflict(OnConflict("day").Exclude("day").Set("hits", Col("hits").Plus(Excluded("hits")))).
+		ToSQL()
+	assert.Equal(s, "INSERT INTO stats (day, hits) VALUES (?, ?) ON DUPLICATE KEY UPDATE hits=(hits + VALUES(hits))")
+	assert.Equal(v, []any{"2024-05-01", 3})
+
+	s, _ = b.Insert("stats").Add("day", "2024-05-01").OnConflict(OnConflict("day").DoNothing()).ToSQL()
+	assert.Equal(s, "INSERT IGNORE INTO stats (day) VALUES (?)")
+
+	assert.Panics(func() {
+		b.Insert("stats").Add("day", "2024-05-01").OnConflict(OnConstraint("stats_day")).ToSQL()
+	})
+	assert.Panics(func() {
+		b.Insert("stats").Add("day", "2024-05-01").OnConflict(OnConflict("day").Where(IsNull("locked"))).ToSQL()
+	})
+}
@@ -2,13 +2,26 @@ package query
 
 import (
 	"fmt"
+	"strings"
 )
 
 type Delete struct {
 	Table   string
 	Dialect Dialect
 
-	where Where
+	where     Where
+	using     []string
+	joins     []Join
+	returning []string
+	orderBy   []Order
+	limit     int
+
+	defaultScopes []Scope
+	unscoped      bool
+	tenant        *Tenant
+	softDelete    string
+	hardDelete    bool
+	clock         Clock
 }
 
 func (d *Delete) SetDialect(dialect Dialect) *Delete {
@@ -25,16 +38,190 @@ func (d *Delete) Where(where Where) *Delete {
 	return d
 }
 
+// Adds tables that can be referenced in the WHERE clause (DELETE ... USING on
+// PostgreSQL, a multi-table delete on MySQL)
+func (d *Delete) Using(tables ...string) *Delete {
+	d.using = append(d.using, tables...)
+	return d
+}
+
+// Joins another table, on PostgreSQL the condition of the first join is moved
+// into the WHERE clause.
+func (d *Delete) Join(table string, on Where) *Delete {
+	d.joins = append(d.joins, Join{
+		Join:  "INNER",
+		Table: table,
+		On:    on,
+	})
+	return d
+}
+
+// Left joins another table, this can't be the first join on PostgreSQL
+func (d *Delete) LeftJoin(table string, on Where) *Delete {
+	d.joins = append(d.joins, Join{
+		Join:  "LEFT",
+		Table: table,
+		On:    on,
+	})
+	return d
+}
+
+// Returns the given columns of the deleted rows (PostgreSQL and SQLite)
+func (d *Delete) Returning(columns ...string) *Delete {
+	d.returning = append(d.returning, columns...)
+	return d
+}
+
+// Deletes rows in the given order, only useful combined with Limit (MySQL)
+func (d *Delete) Order(orders ...Order) *Delete {
+	d.orderBy = append(d.orderBy, orders...)
+	return d
+}
+
+// Deletes at most n rows (MySQL)
+func (d *Delete) Limit(n int) *Delete {
+	d.limit = n
+	return d
+}
+
+// Applies the given scopes to the statement
+func (d *Delete) Scopes(scopes ...Scope) *Delete {
+	for _, scope := range scopes {
+		if scope.Delete != nil {
+			scope.Delete(d)
+		}
+	}
+	return d
+}
+
+// Skips the default scopes registered on the builder for this table
+func (d *Delete) Unscoped() *Delete {
+	d.unscoped = true
+	return d
+}
+
+// Whether scopes or the tenant filter still need to be applied, soft deletes
+// are handled by ToSQL itself
+func (d *Delete) hasDefaults() bool {
+	return (len(d.defaultScopes) > 0 && !d.unscoped) || d.tenant != nil
+}
+
+// Applies the builder defaults, only use this on a clone
+func (d *Delete) applyDefaults() {
+	if !d.unscoped {
+		d.Scopes(d.defaultScopes...)
+	}
+	if d.tenant != nil {
+		d.applyTenant(d.tenant)
+	}
+	d.defaultScopes = nil
+	d.tenant = nil
+}
+
+// Returns a deep copy of the statement, which can be modified independently
+func (d *Delete) Clone() *Delete {
+	c := *d
+	c.where = d.where.Clone()
+	c.using = append([]string(nil), d.using...)
+	c.joins = cloneJoins(d.joins)
+	c.returning = append([]string(nil), d.returning...)
+	c.orderBy = append([]Order(nil), d.orderBy...)
+	return &c
+}
+
 func (d *Delete) ToSQL() (string, []any) {
-	query := ""
+	if d.hasDefaults() {
+		c := d.Clone()
+		c.applyDefaults()
+		return c.ToSQL()
+	}
+
+	d.checkSupported()
+	if d.softDelete != "" && !d.hardDelete {
+		return d.softDeleteSQL()
+	}
+
+	b := strings.Builder{}
 	vars := make([]any, 0)
+	where := d.where
 
-	query = fmt.Sprintf("DELETE FROM %s", d.Table)
-	where, whereVars := d.where.Generate(0, d.Dialect)
-	if where != "" {
-		query = fmt.Sprintf("%s WHERE %s", query, where)
+	multiTable := len(d.using) > 0 || len(d.joins) > 0
+	switch {
+	case !multiTable:
+		b.WriteString("DELETE FROM ")
+		b.WriteString(d.Table)
+	case d.Dialect.UseDeleteUsing():
+		b.WriteString("DELETE FROM ")
+		b.WriteString(d.Table)
+		b.WriteString(" USING ")
+		using := d.using
+		if len(d.joins) > 0 {
+			first := d.joins[0]
+			if first.Join != "INNER" {
+				panic(fmt.Sprintf("The first join of a delete can't be a %s JOIN", first.Join))
+			}
+			using = append(append([]string(nil), using...), first.Table)
+			where = andWhere(first.On, where)
+		}
+		b.WriteString(strings.Join(using, ", "))
+		if len(d.joins) > 1 {
+			q, v := generateJoins(d.joins[1:], len(vars), d.Dialect)
+			b.WriteString(q)
+			vars = append(vars, v...)
+		}
+	default:
+		b.WriteString(fmt.Sprintf("DELETE %s FROM %s", tableAlias(d.Table), d.Table))
+		for _, table := range d.using {
+			b.WriteString(" CROSS JOIN ")
+			b.WriteString(table)
+		}
+		q, v := generateJoins(d.joins, len(vars), d.Dialect)
+		b.WriteString(q)
+		vars = append(vars, v...)
 	}
-	vars = append(vars, whereVars...)
 
-	return query, vars
+	q, v := where.Generate(len(vars), d.Dialect)
+	if q != "" {
+		b.WriteString(" WHERE ")
+		b.WriteString(q)
+	}
+	vars = append(vars, v...)
+
+	if len(d.orderBy) > 0 {
+		q, v := generateOrders(d.orderBy, len(vars), d.Dialect)
+		b.WriteString(" ORDER BY ")
+		b.WriteString(q)
+		vars = append(vars, v...)
+	}
+	if d.limit > 0 {
+		b.WriteString(fmt.Sprintf(" LIMIT %d", d.limit))
+	}
+	if len(d.returning) > 0 {
+		b.WriteString(" RETURNING ")
+		b.WriteString(strings.Join(d.returning, ", "))
+	}
+
+	return b.String(), vars
+}
+
+// Panics when the statement uses a form the dialect doesn't support
+func (d *Delete) checkSupported() {
+	multiTable := len(d.using) > 0 || len(d.joins) > 0
+	if multiTable && !d.Dialect.SupportsDeleteJoin() {
+		panic("Dialect does not support deleting with USING or joins")
+	}
+	if len(d.returning) > 0 && !d.Dialect.SupportsReturning() {
+		panic("Dialect does not support RETURNING")
+	}
+	if len(d.orderBy) > 0 || d.limit > 0 {
+		if !d.Dialect.SupportsDeleteLimit() {
+			panic("Dialect does not support ORDER BY or LIMIT in deletes")
+		}
+		if multiTable {
+			panic("ORDER BY and LIMIT can't be used in multi-table deletes")
+		}
+	}
+	if d.softDelete != "" && !d.hardDelete && (multiTable || len(d.orderBy) > 0 || d.limit > 0) {
+		panic("Soft deletes can't use USING, joins, ORDER BY or LIMIT, use HardDelete()")
+	}
 }
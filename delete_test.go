@@ -93,3 +93,88 @@ func TestDeleteNum(t *testing.T) {
 	assert.Equal(s, "DELETE FROM customer")
 	assert.Equal(len(v), 0)
 }
+
+// Updates with FROM, deletes with JOIN
+type deleteJoinDialect struct {
+	PostgreSQLDialect
+}
+
+func (d deleteJoinDialect) UseDeleteUsing() bool {
+	return false
+}
+
+func TestDeleteMultiTable(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	pg := NewBuilder(PostgreSQLDialect{})
+	s, v := pg.Delete("sessions s", FieldLessThan("s.expires", 100)).
+		Using("users u").
+		Where(Expr("u.id=s.user AND u.active=?", false)).
+		Returning("s.id", "s.user").
+		ToSQL()
+	assert.Equal(s, "DELETE FROM sessions s USING users u WHERE s.expires<$1 AND u.id=s.user AND u.active=$2 RETURNING s.id, s.user")
+	assert.Equal(v, []any{100, false})
+
+	s, v = pg.Delete("sessions s", FieldEquals("s.kind", "web")).
+		Join("users u", And(Expr("u.id=s.user"), FieldEquals("u.active", false))).
+		LeftJoin("devices d", Expr("d.id=s.device AND d.trusted=?", true)).
+		Where(IsNull("d.id")).
+		ToSQL()
+	assert.Equal(s, "DELETE FROM sessions s USING users u LEFT JOIN devices d ON d.id=s.device AND d.trusted=$1 WHERE (u.id=s.user AND u.active=$2) AND s.kind=$3 AND d.id IS NULL")
+	assert.Equal(v, []any{true, false, "web"})
+
+	my := NewBuilder(MySQLDialect{})
+	s, v = my.Delete("sessions s", FieldEquals("s.kind", "web")).
+		Join("users u", And(Expr("u.id=s.user"), FieldEquals("u.active", false))).
+		ToSQL()
+	assert.Equal(s, "DELETE s FROM sessions s INNER JOIN users u ON (u.id=s.user AND u.active=?) WHERE s.kind=?")
+	assert.Equal(v, []any{false, "web"})
+
+	s, v = my.Delete("sessions", Expr("users.id=sessions.user")).Using("users").ToSQL()
+	assert.Equal(s, "DELETE sessions FROM sessions CROSS JOIN users WHERE users.id=sessions.user")
+	assert.Equal(v, []any{})
+
+	tenant := pg.WithTenant(Tenant{Column: "account", Value: 3})
+	s, v = tenant.Delete("sessions s", All()).Using("users u").Where(Expr("u.id=s.user")).ToSQL()
+	assert.Equal(s, "DELETE FROM sessions s USING users u WHERE u.id=s.user AND s.account=$1 AND u.account=$2")
+	assert.Equal(v, []any{3, 3})
+
+	// Deletes don't follow the UPDATE ... FROM syntax of the dialect
+	s, _ = NewBuilder(deleteJoinDialect{}).Delete("sessions s", FieldEquals("s.kind", "web")).Join("users u", Expr("u.id=s.user")).ToSQL()
+	assert.Equal(s, "DELETE s FROM sessions s INNER JOIN users u ON u.id=s.user WHERE s.kind=$1")
+
+	assert.Panics(func() {
+		NewBuilder(SqliteDialect{}).Delete("sessions", All()).Using("users").ToSQL()
+	})
+	assert.Panics(func() {
+		pg.Delete("sessions", All()).LeftJoin("users", All()).ToSQL()
+	})
+}
+
+func TestDeleteLimit(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	my := NewBuilder(MySQLDialect{})
+	s, v := my.Delete("events", FieldLessThan("created", 100)).Order(Asc("created"), Desc("id")).Limit(1000).ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE created<? ORDER BY created, id DESC LIMIT 1000")
+	assert.Equal(v, []any{100})
+
+	sqlite := NewBuilder(SqliteDialect{})
+	s, v = sqlite.Delete("events", IDEquals(3)).Returning("id").ToSQL()
+	assert.Equal(s, "DELETE FROM events WHERE id=? RETURNING id")
+	assert.Equal(v, []any{3})
+
+	assert.Panics(func() {
+		my.Delete("events", All()).Returning("id").ToSQL()
+	})
+	assert.Panics(func() {
+		NewBuilder(PostgreSQLDialect{}).Delete("events", All()).Limit(10).ToSQL()
+	})
+	assert.Panics(func() {
+		my.Delete("events", All()).Using("users").Limit(10).ToSQL()
+	})
+}
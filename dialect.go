@@ -8,7 +8,33 @@ import (
 type Dialect interface {
 	Placeholder(idx int) string
 	UseLastInsertId() bool
-	MakeUpsert(table string, conflictColumn []string, fields []fieldValue, rows int) string
+	MakeUpsert(table string, conflictColumn []string, fields []fieldValue, rows []string) string
+	MakeConflictUpsert(u conflictUpsert) string
+	Excluded(column string) string
+	MakeGroupingSet(kind string, items []string, sole bool) string
+	SupportsNullsOrder() bool
+	Now() string
+
+	// Whether joined updates use UPDATE ... FROM instead of UPDATE ... JOIN
+	UseUpdateFrom() bool
+	SupportsReturning() bool
+	SupportsDeleteJoin() bool
+	SupportsDeleteLimit() bool
+
+	// Whether joined deletes use DELETE ... USING instead of DELETE ... JOIN
+	UseDeleteUsing() bool
+
+	// The hidden column that identifies a row (e.g. ctid), if any
+	RowID() string
+
+	// Whether upserts support ON CONFLICT targets and DO UPDATE ... WHERE
+	SupportsConflictWhere() bool
+
+	// Whether the DEFAULT keyword can be used as a value
+	SupportsDefault() bool
+
+	// Inserts a row with only default values, rendered after the table name
+	DefaultValues() string
 }
 
 func DialectFromString(dialect string) (Dialect, error) {
@@ -36,24 +62,99 @@ func (d MySQLDialect) UseLastInsertId() bool {
 	return true
 }
 
-func (d MySQLDialect) MakeUpsert(table string, conflictColumn []string, fields []fieldValue, rows int) string {
-	fieldNames := make([]string, 0)
-	placeholders := make([]string, 0)
+func (d MySQLDialect) SupportsNullsOrder() bool {
+	return false
+}
+
+func (d MySQLDialect) UseUpdateFrom() bool {
+	return false
+}
+
+func (d MySQLDialect) SupportsReturning() bool {
+	return false
+}
+
+func (d MySQLDialect) SupportsDeleteJoin() bool {
+	return true
+}
+
+func (d MySQLDialect) SupportsDeleteLimit() bool {
+	return true
+}
+
+func (d MySQLDialect) UseDeleteUsing() bool {
+	return false
+}
+
+func (d MySQLDialect) RowID() string {
+	return ""
+}
+
+func (d MySQLDialect) SupportsDefault() bool {
+	return true
+}
+
+func (d MySQLDialect) DefaultValues() string {
+	return "() VALUES ()"
+}
+
+func (d MySQLDialect) SupportsConflictWhere() bool {
+	return false
+}
 
+func (d MySQLDialect) Now() string {
+	return "NOW()"
+}
+
+// Uses REPLACE, or ON DUPLICATE KEY UPDATE when some fields are only set on
+// inserts (e.g. autocreate timestamps)
+func (d MySQLDialect) MakeUpsert(table string, conflictColumn []string, fields []fieldValue, rows []string) string {
+	fieldNames := make([]string, 0)
+	updates := make([]string, 0)
+	insertOnly := false
 	for _, fn := range fields {
 		fieldNames = append(fieldNames, fn.key)
+		if fn.insertOnly {
+			insertOnly = true
+		} else {
+			updates = append(updates, fmt.Sprintf("%s=%s", fn.key, d.Excluded(fn.key)))
+		}
 	}
 
-	placeholderVars := make([]string, 0)
-	for range fields {
-		placeholderVars = append(placeholderVars, "?")
+	values := fmt.Sprintf("VALUES %s", strings.Join(rows, ", "))
+	if !insertOnly {
+		return fmt.Sprintf("REPLACE %s", insertInto(table, fieldNames, values))
 	}
-	placeholder := fmt.Sprintf("(%s)", strings.Join(placeholderVars, ", "))
-	for i := 0; i < rows; i++ {
-		placeholders = append(placeholders, placeholder)
+	return d.MakeConflictUpsert(conflictUpsert{
+		table:   table,
+		columns: fieldNames,
+		source:  values,
+		updates: updates,
+	})
+}
+
+// Uses ON DUPLICATE KEY UPDATE, which always conflicts on any unique index
+func (d MySQLDialect) MakeConflictUpsert(u conflictUpsert) string {
+	if !d.SupportsConflictWhere() && (u.constraint != "" || u.targetWhere != "" || u.where != "") {
+		panic("MySQL does not support conflict constraints, conflict target predicates or WHERE in upserts")
 	}
+	insert := insertInto(u.table, u.columns, u.source)
+	if len(u.updates) == 0 {
+		return fmt.Sprintf("INSERT IGNORE %s", insert)
+	}
+	return fmt.Sprintf("INSERT %s ON DUPLICATE KEY UPDATE %s", insert, strings.Join(u.updates, ", "))
+}
 
-	return fmt.Sprintf("REPLACE INTO %s (%s) VALUES %s", table, strings.Join(fieldNames, ", "), strings.Join(placeholders, ", "))
+func (d MySQLDialect) Excluded(column string) string {
+	return fmt.Sprintf("VALUES(%s)", column)
+}
+
+// Only supports ROLLUP over the full GROUP BY list, using WITH ROLLUP
+func (d MySQLDialect) MakeGroupingSet(kind string, items []string, sole bool) string {
+	if kind != "ROLLUP" || !sole {
+		panic(fmt.Sprintf("MySQL only supports ROLLUP as the sole GROUP BY element, not %s", kind))
+	}
+	return fmt.Sprintf("%s WITH ROLLUP", strings.Join(items, ", "))
 }
 
 // Generates queries using numbered placeholders
@@ -68,10 +169,66 @@ func (d SqliteDialect) UseLastInsertId() bool {
 	return true
 }
 
-func (d SqliteDialect) MakeUpsert(table string, conflictColumn []string, fields []fieldValue, rows int) string {
+func (d SqliteDialect) SupportsNullsOrder() bool {
+	return true
+}
+
+func (d SqliteDialect) UseUpdateFrom() bool {
+	return true
+}
+
+func (d SqliteDialect) SupportsReturning() bool {
+	return true
+}
+
+func (d SqliteDialect) SupportsDeleteJoin() bool {
+	return false
+}
+
+func (d SqliteDialect) SupportsDeleteLimit() bool {
+	return false
+}
+
+func (d SqliteDialect) UseDeleteUsing() bool {
+	return false
+}
+
+func (d SqliteDialect) RowID() string {
+	return "rowid"
+}
+
+func (d SqliteDialect) SupportsDefault() bool {
+	return false
+}
+
+func (d SqliteDialect) DefaultValues() string {
+	return "DEFAULT VALUES"
+}
+
+func (d SqliteDialect) SupportsConflictWhere() bool {
+	return true
+}
+
+func (d SqliteDialect) Now() string {
+	return "datetime('now')"
+}
+
+func (d SqliteDialect) MakeUpsert(table string, conflictColumn []string, fields []fieldValue, rows []string) string {
 	return postgreSQLUpsert(table, conflictColumn, fields, rows)
 }
 
+func (d SqliteDialect) MakeConflictUpsert(u conflictUpsert) string {
+	return postgreSQLConflictUpsert(u)
+}
+
+func (d SqliteDialect) Excluded(column string) string {
+	return fmt.Sprintf("EXCLUDED.%s", column)
+}
+
+func (d SqliteDialect) MakeGroupingSet(kind string, items []string, sole bool) string {
+	panic(fmt.Sprintf("SQLite does not support %s", kind))
+}
+
 // Generates queries using numbered placeholders
 type PostgreSQLDialect struct {
 }
@@ -84,31 +241,77 @@ func (d PostgreSQLDialect) UseLastInsertId() bool {
 	return false
 }
 
-func (d PostgreSQLDialect) MakeUpsert(table string, conflictColumn []string, fields []fieldValue, rows int) string {
+func (d PostgreSQLDialect) SupportsNullsOrder() bool {
+	return true
+}
+
+func (d PostgreSQLDialect) UseUpdateFrom() bool {
+	return true
+}
+
+func (d PostgreSQLDialect) SupportsReturning() bool {
+	return true
+}
+
+func (d PostgreSQLDialect) SupportsDeleteJoin() bool {
+	return true
+}
+
+func (d PostgreSQLDialect) SupportsDeleteLimit() bool {
+	return false
+}
+
+func (d PostgreSQLDialect) UseDeleteUsing() bool {
+	return true
+}
+
+func (d PostgreSQLDialect) RowID() string {
+	return "ctid"
+}
+
+func (d PostgreSQLDialect) SupportsDefault() bool {
+	return true
+}
+
+func (d PostgreSQLDialect) DefaultValues() string {
+	return "DEFAULT VALUES"
+}
+
+func (d PostgreSQLDialect) SupportsConflictWhere() bool {
+	return true
+}
+
+func (d PostgreSQLDialect) Now() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (d PostgreSQLDialect) MakeUpsert(table string, conflictColumn []string, fields []fieldValue, rows []string) string {
 	return postgreSQLUpsert(table, conflictColumn, fields, rows)
 }
 
+func (d PostgreSQLDialect) MakeConflictUpsert(u conflictUpsert) string {
+	return postgreSQLConflictUpsert(u)
+}
+
+func (d PostgreSQLDialect) Excluded(column string) string {
+	return fmt.Sprintf("EXCLUDED.%s", column)
+}
+
+func (d PostgreSQLDialect) MakeGroupingSet(kind string, items []string, sole bool) string {
+	return fmt.Sprintf("%s (%s)", kind, strings.Join(items, ", "))
+}
+
 // Shared functionality
 func numberedPlaceholder(idx int) string {
 	return fmt.Sprintf("$%d", idx+1)
 }
 
-func postgreSQLUpsert(table string, conflictColumn []string, fields []fieldValue, rows int) string {
+func postgreSQLUpsert(table string, conflictColumn []string, fields []fieldValue, rows []string) string {
 	fieldNames := make([]string, 0)
-	placeholders := make([]string, 0)
-
 	for _, fn := range fields {
 		fieldNames = append(fieldNames, fn.key)
 	}
 
-	for i := 0; i < rows; i++ {
-		placeholderVars := make([]string, 0)
-		for j := range fields {
-			placeholderVars = append(placeholderVars, numberedPlaceholder(i*len(fields)+j))
-		}
-		placeholder := fmt.Sprintf("(%s)", strings.Join(placeholderVars, ", "))
-		placeholders = append(placeholders, placeholder)
-	}
 	conflictCol := ""
 	if len(conflictColumn) > 0 {
 		conflictCol = fmt.Sprintf(" (%s)", strings.Join(conflictColumn, ", "))
@@ -116,10 +319,13 @@ func postgreSQLUpsert(table string, conflictColumn []string, fields []fieldValue
 	action := "NOTHING"
 	if len(conflictColumn) > 0 {
 		updates := make([]string, 0)
-		for _, fn := range fieldNames {
-			updates = append(updates, fmt.Sprintf("%s=EXCLUDED.%s", fn, fn))
+		for _, fn := range fields {
+			if fn.insertOnly {
+				continue
+			}
+			updates = append(updates, fmt.Sprintf("%s=EXCLUDED.%s", fn.key, fn.key))
 		}
 		action = fmt.Sprintf("UPDATE SET %s", strings.Join(updates, ", "))
 	}
-	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT%s DO %s", table, strings.Join(fieldNames, ", "), strings.Join(placeholders, ", "), conflictCol, action)
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT%s DO %s", table, strings.Join(fieldNames, ", "), strings.Join(rows, ", "), conflictCol, action)
 }
@@ -0,0 +1,67 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Returned when an update with a version column did not match any row,
+// meaning the object was modified (or deleted) since it was loaded.
+var ErrStaleObject = errors.New("Stale object")
+
+// Returned when an update created with WithChanges has nothing to update, no
+// statement is executed in that case.
+var ErrNoChanges = errors.New("No changes")
+
+// Executes statements, implemented by *sql.DB, *sql.Tx and *sql.Conn
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Runs queries, implemented by *sql.DB, *sql.Tx and *sql.Conn
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Executes the statement.
+//
+// Returns ErrStaleObject if an update of an object with a version column
+// (see With) did not affect any rows, or ErrNoChanges if an update created
+// with WithChanges has nothing to update.
+func (i *InsertUpdate) Exec(ctx context.Context, db Execer) (sql.Result, error) {
+	if i.isUnchanged() {
+		return nil, ErrNoChanges
+	}
+
+	query, args := i.ToSQL()
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if i.versioned {
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, ErrStaleObject
+		}
+	}
+	return res, nil
+}
+
+func incrementVersion(v reflect.Value) any {
+	next := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		next.SetInt(v.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		next.SetUint(v.Uint() + 1)
+	default:
+		panic(fmt.Sprintf("Version column must be an integer, got %s", v.Type()))
+	}
+	return next.Interface()
+}
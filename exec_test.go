@@ -0,0 +1,77 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResult int64
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return 0, errors.New("Not supported")
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
+
+type fakeExecer struct {
+	rows    []int64
+	queries []string
+	args    [][]any
+}
+
+func (e *fakeExecer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	e.queries = append(e.queries, query)
+	e.args = append(e.args, args)
+	n := e.rows[0]
+	e.rows = e.rows[1:]
+	return fakeResult(n), nil
+}
+
+func TestVersionUpdate(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Document struct {
+		ID      int64  `db:"id,autoincrement"`
+		Title   string `db:"title"`
+		Version int32  `db:"version,version"`
+	}
+
+	b := NewBuilder(PostgreSQLDialect{})
+	doc := &Document{ID: 3, Title: "Hello", Version: 4}
+
+	s, v := b.Update("documents", IDEquals(3)).With(doc).ToSQL()
+	assert.Equal(s, "UPDATE documents SET title=$1, version=$2 WHERE id=$3 AND documents.version=$4")
+	assert.Equal(v, []any{"Hello", int32(5), 3, int32(4)})
+
+	// Joined tables can have a version column too
+	s, v = b.Update("documents d", FieldEquals("d.id", 3)).Join("folders f", Expr("f.id=d.folder")).With(doc).ToSQL()
+	assert.Equal(s, "UPDATE documents d SET title=$1, version=$2 FROM folders f WHERE f.id=d.folder AND d.id=$3 AND d.version=$4")
+	assert.Equal(v, []any{"Hello", int32(5), 3, int32(4)})
+
+	s, v = b.Insert("documents").With(doc).ToSQL()
+	assert.Equal(s, "INSERT INTO documents (id, title, version) VALUES ($1, $2, $3)")
+	assert.Equal(v, []any{int64(3), "Hello", int32(4)})
+
+	db := &fakeExecer{rows: []int64{1, 0}}
+	_, err := b.Update("documents", IDEquals(3)).With(doc).Exec(context.Background(), db)
+	assert.NoError(err)
+
+	_, err = b.Update("documents", IDEquals(3)).With(doc).Exec(context.Background(), db)
+	assert.True(errors.Is(err, ErrStaleObject))
+	assert.Len(db.queries, 2)
+
+	// Without a version column, no rows affected is not an error
+	db = &fakeExecer{rows: []int64{0}}
+	_, err = b.Update("documents", IDEquals(3)).Add("title", "Hello").Exec(context.Background(), db)
+	assert.NoError(err)
+	assert.Equal(db.queries, []string{"UPDATE documents SET title=$1 WHERE id=$2"})
+	assert.Equal(db.args, [][]any{{"Hello", 3}})
+}
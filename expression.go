@@ -0,0 +1,290 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type expressionMode int
+
+const (
+	columnExpression expressionMode = iota
+	paramExpression
+	literalExpression
+	funcExpression
+	castExpression
+	binaryExpression
+	caseExpression
+	excludedExpression
+)
+
+// A composable SQL expression (column, parameter, function call, ...) that
+// can be used as a select field, in ORDER BY and GROUP BY, as an insert or
+// update value and as a Where value.
+type Expression struct {
+	mode  expressionMode
+	name  string
+	value any
+	alias string
+
+	args   []Expression
+	values []any
+
+	operand   *Expression
+	whens     []caseWhen
+	elseValue *Expression
+}
+
+type caseWhen struct {
+	match  Expression
+	cond   *Where
+	result Expression
+}
+
+// References a column (or any other identifier)
+func Col(name string) Expression {
+	return Expression{
+		mode: columnExpression,
+		name: name,
+	}
+}
+
+// A value, bound as a parameter
+func Param(value any) Expression {
+	return Expression{
+		mode:  paramExpression,
+		value: value,
+	}
+}
+
+// Literal SQL, use ? for parameters (?? for a literal ?)
+func Literal(sql string, args ...any) Expression {
+	return Expression{
+		mode:   literalExpression,
+		name:   sql,
+		values: args,
+	}
+}
+
+// Calls a function, arguments that aren't expressions are bound as parameters
+func Func(name string, args ...any) Expression {
+	return Expression{
+		mode: funcExpression,
+		name: name,
+		args: toExpressions(args),
+	}
+}
+
+// Casts a value to the given SQL type
+func Cast(value any, sqlType string) Expression {
+	return Expression{
+		mode: castExpression,
+		name: sqlType,
+		args: []Expression{toExpression(value)},
+	}
+}
+
+// A CASE expression where each When takes a Where condition:
+//
+//	Case().When(FieldEquals("status", 1), "active").Else("inactive")
+func Case() Expression {
+	return Expression{
+		mode: caseExpression,
+	}
+}
+
+// A simple CASE expression that compares operand against each When value
+func CaseValue(operand any) Expression {
+	o := toExpression(operand)
+	return Expression{
+		mode:    caseExpression,
+		operand: &o,
+	}
+}
+
+func (e Expression) Plus(value any) Expression {
+	return e.binary("+", value)
+}
+
+func (e Expression) Minus(value any) Expression {
+	return e.binary("-", value)
+}
+
+func (e Expression) Times(value any) Expression {
+	return e.binary("*", value)
+}
+
+func (e Expression) Div(value any) Expression {
+	return e.binary("/", value)
+}
+
+func (e Expression) binary(op string, value any) Expression {
+	return Expression{
+		mode: binaryExpression,
+		name: op,
+		args: []Expression{e, toExpression(value)},
+	}
+}
+
+// Adds a branch to a CASE expression, the condition is a Where for Case() and
+// a value to compare with for CaseValue().
+func (e Expression) When(condition, result any) Expression {
+	if e.mode != caseExpression {
+		panic("When() can only be used on a CASE expression")
+	}
+	w := caseWhen{
+		result: toExpression(result),
+	}
+	if e.operand == nil {
+		cond, ok := condition.(Where)
+		if !ok {
+			panic("When() needs a Where condition, use CaseValue() to compare values")
+		}
+		w.cond = &cond
+	} else {
+		w.match = toExpression(condition)
+	}
+	e.whens = append(append([]caseWhen(nil), e.whens...), w)
+	return e
+}
+
+// Sets the result of a CASE expression when no branch matches
+func (e Expression) Else(result any) Expression {
+	if e.mode != caseExpression {
+		panic("Else() can only be used on a CASE expression")
+	}
+	r := toExpression(result)
+	e.elseValue = &r
+	return e
+}
+
+// Names the expression when used as a select field
+func (e Expression) As(alias string) Expression {
+	e.alias = alias
+	return e
+}
+
+func (e Expression) Asc() Order {
+	return Order{
+		expr: &e,
+	}
+}
+
+func (e Expression) Desc() Order {
+	return Order{
+		expr: &e,
+		Desc: true,
+	}
+}
+
+// Compares the expression to a value
+func (e Expression) Compare(op string, value any) Where {
+	return Where{
+		mode:   exprOpClause,
+		op:     op,
+		value:  value,
+		values: []any{e},
+	}
+}
+
+func (e Expression) Generate(offset int, dialect Dialect) (string, []any) {
+	switch e.mode {
+	case columnExpression:
+		return e.name, nil
+	case paramExpression:
+		return generateValue(e.value, offset, dialect)
+	case literalExpression:
+		return replacePlaceholders(e.name, e.values, offset, dialect)
+	case funcExpression:
+		args, vars := generateExpressions(e.args, offset, dialect)
+		return fmt.Sprintf("%s(%s)", e.name, strings.Join(args, ", ")), vars
+	case castExpression:
+		q, v := e.args[0].Generate(offset, dialect)
+		return fmt.Sprintf("CAST(%s AS %s)", q, e.name), v
+	case binaryExpression:
+		args, vars := generateExpressions(e.args, offset, dialect)
+		for j, arg := range e.args {
+			// Literal SQL can contain operators of its own
+			if arg.mode == literalExpression {
+				args[j] = fmt.Sprintf("(%s)", args[j])
+			}
+		}
+		return fmt.Sprintf("(%s %s %s)", args[0], e.name, args[1]), vars
+	case caseExpression:
+		return e.generateCase(offset, dialect)
+	case excludedExpression:
+		return dialect.Excluded(e.name), nil
+	default:
+		panic(fmt.Sprintf("Unknown mode %#v", e.mode))
+	}
+}
+
+func (e Expression) generateCase(offset int, dialect Dialect) (string, []any) {
+	b := strings.Builder{}
+	vars := make([]any, 0)
+	write := func(prefix string, expr Expression) {
+		q, v := expr.Generate(offset+len(vars), dialect)
+		b.WriteString(prefix)
+		b.WriteString(q)
+		vars = append(vars, v...)
+	}
+
+	b.WriteString("CASE")
+	if e.operand != nil {
+		write(" ", *e.operand)
+	}
+	for _, w := range e.whens {
+		if w.cond != nil {
+			q, v := w.cond.Generate(offset+len(vars), dialect)
+			if q == "" {
+				q = "1=1"
+			}
+			b.WriteString(" WHEN ")
+			b.WriteString(q)
+			vars = append(vars, v...)
+		} else {
+			write(" WHEN ", w.match)
+		}
+		write(" THEN ", w.result)
+	}
+	if e.elseValue != nil {
+		write(" ELSE ", *e.elseValue)
+	}
+	b.WriteString(" END")
+	return b.String(), vars
+}
+
+// Generates the expression as a select field, including its alias
+func (e Expression) generateField(offset int, dialect Dialect) (string, []any) {
+	q, v := e.Generate(offset, dialect)
+	if e.alias != "" {
+		q = fmt.Sprintf("%s AS %s", q, e.alias)
+	}
+	return q, v
+}
+
+func generateExpressions(exprs []Expression, offset int, dialect Dialect) ([]string, []any) {
+	parts := make([]string, 0)
+	vars := make([]any, 0)
+	for _, expr := range exprs {
+		q, v := expr.Generate(offset+len(vars), dialect)
+		parts = append(parts, q)
+		vars = append(vars, v...)
+	}
+	return parts, vars
+}
+
+func toExpression(value any) Expression {
+	if e, ok := value.(Expression); ok {
+		return e
+	}
+	return Param(value)
+}
+
+func toExpressions(values []any) []Expression {
+	exprs := make([]Expression, 0)
+	for _, value := range values {
+		exprs = append(exprs, toExpression(value))
+	}
+	return exprs
+}
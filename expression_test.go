@@ -0,0 +1,127 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpression(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	e := Func("coalesce", Col("nickname"), Cast(Col("id"), "text"), "anonymous")
+	s, v := e.Generate(0, PostgreSQLDialect{})
+	assert.Equal(s, "coalesce(nickname, CAST(id AS text), $1)")
+	assert.Equal(v, []any{"anonymous"})
+
+	e = Col("price").Times(Param(1.21)).Minus(Literal("discount * ?", 2)).Div(100)
+	s, v = e.Generate(3, PostgreSQLDialect{})
+	assert.Equal(s, "(((price * $4) - (discount * $5)) / $6)")
+	assert.Equal(v, []any{1.21, 2, 100})
+
+	// Literals keep their precedence
+	s, _ = Col("x").Times(Literal("a + b")).Generate(0, PostgreSQLDialect{})
+	assert.Equal(s, "(x * (a + b))")
+	s, _ = Literal("a - b").Minus(Col("c")).Generate(0, PostgreSQLDialect{})
+	assert.Equal(s, "((a - b) - c)")
+
+	e = CaseValue(Col("status")).When(1, "active").When(2, Col("label")).Else(Func("upper", "unknown"))
+	s, v = e.Generate(0, MySQLDialect{})
+	assert.Equal(s, "CASE status WHEN ? THEN ? WHEN ? THEN label ELSE upper(?) END")
+	assert.Equal(v, []any{1, "active", 2, "unknown"})
+
+	// Branches don't affect each other
+	base := CaseValue(Col("status")).When(1, "a")
+	b1 := base.When(2, "b")
+	b2 := base.When(3, "c")
+	s, _ = b1.Generate(0, MySQLDialect{})
+	assert.Equal(s, "CASE status WHEN ? THEN ? WHEN ? THEN ? END")
+	s, v = b2.Generate(0, MySQLDialect{})
+	assert.Equal(s, "CASE status WHEN ? THEN ? WHEN ? THEN ? END")
+	assert.Equal(v, []any{1, "a", 3, "c"})
+
+	assert.Panics(func() {
+		Col("x").When(1, 2)
+	})
+}
+
+func TestExpressionClauses(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, v := b.Select("id", "orders").
+		AddFields(
+			Col("price").Times(Col("quantity")).As("total"),
+			Func("date_trunc", "day", Col("created")).As("day"),
+		).
+		Where(FieldGreaterThan("price", Col("min_price").Plus(5))).
+		Where(Func("lower", Col("country")).Compare("=", "be")).
+		Group(GroupExpression(Func("date_trunc", "day", Col("created")))).
+		Order(Func("greatest", Col("price"), 10).Desc(), Asc("id")).
+		ToSQL()
+	assert.Equal(s, "SELECT id, (price * quantity) AS total, date_trunc($1, created) AS day FROM orders WHERE price>(min_price + $2) AND lower(country)=$3 GROUP BY date_trunc($4, created) ORDER BY greatest(price, $5) DESC, id")
+	assert.Equal(v, []any{"day", 5, "be", "day", 10})
+
+	s, v = b.Select("", "orders", 3).AddFields(Func("count", Col("*"))).Where(Expr("shop=?", 3)).ToSQL()
+	assert.Equal(s, "SELECT count(*) FROM orders WHERE shop=$2")
+	assert.Equal(v, []any{3, 3})
+
+	s, v = b.Update("counters", IDEquals(4)).
+		Add("hits", Col("hits").Plus(1)).
+		Add("label", Func("concat", Col("label"), "!")).
+		ToSQL()
+	assert.Equal(s, "UPDATE counters SET hits=(hits + $1), label=concat(label, $2) WHERE id=$3")
+	assert.Equal(v, []any{1, "!", 4})
+
+	s, v = b.Insert("events").Add("name", "signup").Add("day", Cast(Now(), "date")).ToSQL()
+	assert.Equal(s, "INSERT INTO events (name, day) VALUES ($1, CAST(CURRENT_TIMESTAMP AS date))")
+	assert.Equal(v, []any{"signup"})
+
+	m := NewBuilder(MySQLDialect{})
+	s, v = m.Select("*", "orders").Order(Func("nullif", Col("rank"), 0).Asc().NullsLast()).ToSQL()
+	assert.Equal(s, "SELECT * FROM orders ORDER BY nullif(rank, ?) IS NULL, nullif(rank, ?)")
+	assert.Equal(v, []any{0, 0})
+}
+
+func TestCase(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	status := Case().
+		When(FieldEquals("status", 1), "active").
+		When(And(FieldEquals("status", 2), FieldGreaterThan("age", 30)), "stale").
+		Else("inactive")
+
+	s, v := b.Select("id", "users").
+		Where(FieldEquals("org", 5)).
+		AddFields(status.As("label")).
+		Order(Case().When(IsNull("priority"), 1).Else(0).Asc(), Asc("id")).
+		ToSQL()
+	assert.Equal(s, "SELECT id, CASE WHEN status=$1 THEN $2 WHEN (status=$3 AND age>$4) THEN $5 ELSE $6 END AS label FROM users WHERE org=$7 ORDER BY CASE WHEN priority IS NULL THEN $8 ELSE $9 END, id")
+	assert.Equal(v, []any{1, "active", 2, 30, "stale", "inactive", 5, 1, 0})
+
+	s, v = b.Update("users", IDEquals(3)).
+		Add("name", "Bob").
+		Add("level", Case().When(FieldGreaterThan("score", 100), Col("level").Plus(1)).Else(Col("level"))).
+		ToSQL()
+	assert.Equal(s, "UPDATE users SET name=$1, level=CASE WHEN score>$2 THEN (level + $3) ELSE level END WHERE id=$4")
+	assert.Equal(v, []any{"Bob", 100, 1, 3})
+
+	s, v = NewBuilder(MySQLDialect{}).Select("", "orders").
+		AddFields(Func("SUM", Case().When(FieldEquals("paid", true), Col("total")).Else(0)).As("paid")).
+		ToSQL()
+	assert.Equal(s, "SELECT SUM(CASE WHEN paid=? THEN total ELSE ? END) AS paid FROM orders")
+	assert.Equal(v, []any{true, 0})
+
+	assert.Panics(func() {
+		Case().When(1, 2)
+	})
+}
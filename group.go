@@ -0,0 +1,142 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type groupMode int
+
+const (
+	fieldGroup groupMode = iota
+	exprGroup
+	expressionGroup
+	rollupGroup
+	cubeGroup
+	groupingSetsGroup
+)
+
+// A single element of a GROUP BY clause
+type Group struct {
+	mode   groupMode
+	field  string
+	values []any
+	expr   *Expression
+
+	children []Group
+	sets     [][]Group
+}
+
+func GroupField(field string) Group {
+	return Group{
+		mode:  fieldGroup,
+		field: field,
+	}
+}
+
+// Groups on an expression, use ? for parameters (?? for a literal ?)
+func GroupExpr(expr string, args ...any) Group {
+	return Group{
+		mode:   exprGroup,
+		field:  expr,
+		values: args,
+	}
+}
+
+func GroupExpression(e Expression) Group {
+	return Group{
+		mode: expressionGroup,
+		expr: &e,
+	}
+}
+
+// Generates subtotals for each prefix of the given groups.
+//
+// MySQL only supports this as the sole GROUP BY element (WITH ROLLUP).
+func Rollup(groups ...Group) Group {
+	return Group{
+		mode:     rollupGroup,
+		children: groups,
+	}
+}
+
+// Generates subtotals for all combinations of the given groups.
+func Cube(groups ...Group) Group {
+	return Group{
+		mode:     cubeGroup,
+		children: groups,
+	}
+}
+
+// Groups on each of the given sets, use an empty set for the grand total.
+func GroupingSets(sets ...[]Group) Group {
+	return Group{
+		mode: groupingSetsGroup,
+		sets: sets,
+	}
+}
+
+func (g Group) clone() Group {
+	c := g
+	c.values = cloneArgs(g.values)
+	c.children = cloneGroups(g.children)
+	if g.sets != nil {
+		c.sets = make([][]Group, len(g.sets))
+		for i, set := range g.sets {
+			c.sets[i] = cloneGroups(set)
+		}
+	}
+	return c
+}
+
+func cloneGroups(groups []Group) []Group {
+	if groups == nil {
+		return nil
+	}
+	c := make([]Group, len(groups))
+	for i, g := range groups {
+		c[i] = g.clone()
+	}
+	return c
+}
+
+func (g Group) generate(offset int, dialect Dialect, sole bool) (string, []any) {
+	switch g.mode {
+	case fieldGroup:
+		return g.field, nil
+	case exprGroup:
+		return replacePlaceholders(g.field, g.values, offset, dialect)
+	case expressionGroup:
+		return g.expr.Generate(offset, dialect)
+	case rollupGroup:
+		items, vars := generateGroups(g.children, offset, dialect, false)
+		return dialect.MakeGroupingSet("ROLLUP", items, sole), vars
+	case cubeGroup:
+		items, vars := generateGroups(g.children, offset, dialect, false)
+		return dialect.MakeGroupingSet("CUBE", items, sole), vars
+	case groupingSetsGroup:
+		items := make([]string, 0)
+		vars := make([]any, 0)
+		for _, set := range g.sets {
+			q, v := generateGroups(set, offset+len(vars), dialect, false)
+			items = append(items, fmt.Sprintf("(%s)", strings.Join(q, ", ")))
+			vars = append(vars, v...)
+		}
+		return dialect.MakeGroupingSet("GROUPING SETS", items, sole), vars
+	default:
+		panic(fmt.Sprintf("Unknown mode %#v", g.mode))
+	}
+}
+
+// Renders a list of groups, top indicates the GROUP BY list itself rather than
+// the contents of a grouping construct.
+func generateGroups(groups []Group, offset int, dialect Dialect, top bool) ([]string, []any) {
+	parts := make([]string, 0)
+	vars := make([]any, 0)
+	for _, g := range groups {
+		q, v := g.generate(offset+len(vars), dialect, top && len(groups) == 1)
+		parts = append(parts, q)
+		vars = append(vars, v...)
+	}
+	return parts, vars
+}
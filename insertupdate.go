@@ -3,6 +3,7 @@ package query
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -18,6 +19,18 @@ type fieldValue struct {
 	key   string
 	value any
 	from  *Select
+
+	// Not updated when an upsert conflicts
+	insertOnly bool
+}
+
+// Renders the value of a field, subqueries are wrapped in parentheses
+func (f fieldValue) generate(offset int, dialect Dialect) (string, []any) {
+	if f.from != nil {
+		q, v := f.from.toSQL(offset)
+		return fmt.Sprintf("(%s)", q), v
+	}
+	return generateValue(f.value, offset, dialect)
 }
 
 type InsertUpdate struct {
@@ -26,9 +39,23 @@ type InsertUpdate struct {
 	where          Where
 	fields         []fieldValue
 	fromSelect     *Select
+	fromColumns    []string
 	dialect        Dialect
 	conflictColumn []string
-	returning      string
+	conflict       *Conflict
+	joins          []Join
+	returning      []string
+	returnInto     reflect.Value
+	onlyChanges    bool
+	versioned      bool
+	defaultValues  bool
+
+	defaultScopes []Scope
+	unscoped      bool
+	tenant        *Tenant
+	crossTenant   bool
+	model         *model
+	clock         Clock
 }
 
 func (i *InsertUpdate) Add(key string, value any) *InsertUpdate {
@@ -36,37 +63,131 @@ func (i *InsertUpdate) Add(key string, value any) *InsertUpdate {
 	return i
 }
 
+// ANDs the given clause into the WHERE of an update
+func (i *InsertUpdate) Where(where Where) *InsertUpdate {
+	i.where = andWhere(i.where, where)
+	return i
+}
+
+// Joins another table into an update. PostgreSQL and SQLite render this as
+// UPDATE ... FROM, where the condition of the first join is moved into the
+// WHERE clause.
+func (i *InsertUpdate) Join(table string, on Where) *InsertUpdate {
+	return i.addJoin("INNER", table, on)
+}
+
+// Left joins another table into an update, this can't be the first join on
+// PostgreSQL and SQLite.
+func (i *InsertUpdate) LeftJoin(table string, on Where) *InsertUpdate {
+	return i.addJoin("LEFT", table, on)
+}
+
+func (i *InsertUpdate) addJoin(join, table string, on Where) *InsertUpdate {
+	if i.mode != updateMode {
+		panic("Joins can only be used in updates")
+	}
+	i.joins = append(i.joins, Join{
+		Join:  join,
+		Table: table,
+		On:    on,
+	})
+	return i
+}
+
 func (i *InsertUpdate) AddSelect(key string, s *Select) *InsertUpdate {
 	i.fields = append(i.fields, fieldValue{key: key, from: s})
 	return i
 }
 
+// Adds n to the current value of the column (SET col=(col + n))
+func (i *InsertUpdate) Increment(key string, n any) *InsertUpdate {
+	return i.Add(key, Col(key).Plus(n))
+}
+
+// Subtracts n from the current value of the column (SET col=(col - n))
+func (i *InsertUpdate) Decrement(key string, n any) *InsertUpdate {
+	return i.Add(key, Col(key).Minus(n))
+}
+
+// Sets the column to literal SQL, use ? for parameters (?? for a literal ?)
+func (i *InsertUpdate) SetExpr(key string, sql string, args ...any) *InsertUpdate {
+	return i.Add(key, Literal(sql, args...))
+}
+
+// Sets the column to its default value
+func (i *InsertUpdate) SetDefault(key string) *InsertUpdate {
+	return i.Add(key, Default)
+}
+
+// Sets the column to NULL
+func (i *InsertUpdate) SetNull(key string) *InsertUpdate {
+	return i.Add(key, Raw("NULL"))
+}
+
+// Inserts a row with default values for all columns, this is also what
+// happens when no fields are added. Can't be combined with Add or Select.
+func (i *InsertUpdate) DefaultValues() *InsertUpdate {
+	if i.mode == updateMode {
+		panic("DefaultValues() can't be used in updates")
+	}
+	i.defaultValues = true
+	i.checkDefaultValues()
+	return i
+}
+
+func (i *InsertUpdate) checkDefaultValues() {
+	if i.defaultValues && (len(i.fields) > 0 || i.fromSelect != nil) {
+		panic("DefaultValues() can't be combined with added fields")
+	}
+}
+
 func (i *InsertUpdate) addStructFields(options *InsertUpdateOptions, t reflect.Type, v reflect.Value) {
-	for j := 0; j < t.NumField(); j++ {
-		field := t.Field(j)
-		if field.Anonymous {
-			i.addStructFields(options, field.Type, v.Field(j))
-		}
-		tag := field.Tag.Get("db")
-		parts := strings.Split(tag, ",")
-		if parts[0] == "" || parts[0] == "-" {
-			continue
-		}
-		if len(parts) > 1 {
-			if parts[1] == "autoincrement" {
-				val := v.Field(j)
-				if (i.mode != insertMode || val.IsZero()) && !options.CopyAutoIncrement {
-					continue
-				}
+	now := i.clock.now()
+	structFields(t, v, func(f structField) {
+		if f.hasOption("autoincrement") {
+			if (i.mode != insertMode || f.value.IsZero()) && !options.CopyAutoIncrement {
+				return
 			}
-			if parts[1] == "readonly" && !options.CopyReadOnly {
-				continue
+		}
+		if f.hasOption("readonly") && !options.CopyReadOnly {
+			return
+		}
+		if options.skip(f) || (f.hasOption("omitempty") && f.value.IsZero() && !options.changes) {
+			return
+		}
+		if f.hasOption("autocreate") {
+			if i.mode != updateMode {
+				i.fields = append(i.fields, fieldValue{key: f.column, value: now, insertOnly: true})
 			}
+			return
 		}
-		i.Add(parts[0], v.Field(j).Interface())
-	}
+		if f.hasOption("autoupdate") {
+			i.Add(f.column, now)
+			return
+		}
+		if f.hasOption("version") && i.mode == updateMode {
+			i.Add(f.column, incrementVersion(f.value))
+			i.Where(FieldEquals(fmt.Sprintf("%s.%s", tableAlias(i.Table), f.column), f.value.Interface()))
+			i.versioned = true
+			return
+		}
+		i.Add(f.column, f.columnValue())
+	})
 }
 
+// Adds the fields of a struct, using the db tags as column names. Tag options:
+//
+//   - autoincrement: only copied on inserts with a non-zero value
+//   - readonly: never copied (unless WithReadOnly is passed)
+//   - version: updates increment the column and only match the current version
+//   - autocreate: set to the current time on inserts, never updated
+//   - autoupdate: set to the current time on inserts and updates
+//   - omitempty: skipped when it has the zero value
+//   - prefix: adds the fields of a nested struct, prefixing their columns
+//     with the tag name (e.g. `db:"address_,prefix"`)
+//
+// Maps with string keys are also accepted, their keys are added in sorted
+// order.
 func (i *InsertUpdate) With(obj any, opts ...WithOpt) *InsertUpdate {
 	options := &InsertUpdateOptions{}
 	for _, o := range opts {
@@ -78,78 +199,306 @@ func (i *InsertUpdate) With(obj any, opts ...WithOpt) *InsertUpdate {
 		v = v.Elem()
 	}
 	t := v.Type()
-	if t.Kind() == reflect.Struct {
+	switch {
+	case t.Kind() == reflect.Struct:
 		i.addStructFields(options, t, v)
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		i.addMapFields(options, v)
+	default:
+		panic(fmt.Sprintf("With() needs a struct or a map with string keys, got %s", t))
 	}
 	return i
 }
 
-func (i *InsertUpdate) Select(s *Select) *InsertUpdate {
+func (i *InsertUpdate) addMapFields(options *InsertUpdateOptions, v reflect.Value) {
+	keys := make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if options.skip(structField{column: key}) {
+			continue
+		}
+		i.Add(key, v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())).Interface())
+	}
+}
+
+// Inserts the rows of a select into the given columns (INSERT ... SELECT).
+// Fields set with Add are inserted as constants, added to the select fields.
+//
+// Without columns the select has to match the columns of the table and Add
+// can't be used. A builder with a tenant requires the columns, a selected tenant
+// column is replaced by the tenant.
+func (i *InsertUpdate) Select(s *Select, columns ...string) *InsertUpdate {
 	i.fromSelect = s
+	i.fromColumns = columns
+	return i
+}
+
+// Whether the select of an INSERT ... SELECT fills the column
+func (i *InsertUpdate) selectsColumn(column string) bool {
+	for _, c := range i.fromColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Turns the insert into an upsert with the given conflict handling
+func (i *InsertUpdate) OnConflict(conflict *Conflict) *InsertUpdate {
+	if i.mode == updateMode {
+		panic("OnConflict() can't be used in updates")
+	}
+	i.mode = upsertMode
+	i.conflict = conflict
+	return i
+}
+
+// Returns the given columns of the affected row
+func (i *InsertUpdate) Returning(columns ...string) *InsertUpdate {
+	i.returning = append(i.returning, columns...)
+	return i
+}
+
+// Returns the columns of a struct (db tags) and writes them back into obj
+// when using Scan. Use this to read defaults, generated IDs or columns set by
+// triggers.
+func (i *InsertUpdate) ReturningStruct(obj any) *InsertUpdate {
+	i.returnInto = returningTarget(obj)
+	return i.Returning(structColumns(i.returnInto.Type())...)
+}
+
+// Applies the given scopes to the statement
+func (i *InsertUpdate) Scopes(scopes ...Scope) *InsertUpdate {
+	for _, scope := range scopes {
+		fn := scope.Insert
+		if i.mode == updateMode {
+			fn = scope.Update
+		}
+		if fn != nil {
+			fn(i)
+		}
+	}
 	return i
 }
 
-func (i *InsertUpdate) Returning(field string) *InsertUpdate {
-	i.returning = field
+// Skips the default scopes registered on the builder for this table
+func (i *InsertUpdate) Unscoped() *InsertUpdate {
+	i.unscoped = true
 	return i
 }
 
+// Allows an upsert to overwrite rows of other tenants on dialects that can't
+// restrict the update to the tenant (MySQL), which otherwise panic
+func (i *InsertUpdate) AllowCrossTenantUpsert() *InsertUpdate {
+	i.crossTenant = true
+	return i
+}
+
+// Whether scopes, the tenant or timestamp columns still need to be applied,
+// see Builder
+func (i *InsertUpdate) hasDefaults() bool {
+	return (len(i.defaultScopes) > 0 && !i.unscoped) || i.tenant != nil || i.hasTimestamps()
+}
+
+// Applies the builder defaults, only use this on a clone
+func (i *InsertUpdate) applyDefaults() {
+	if !i.unscoped {
+		i.Scopes(i.defaultScopes...)
+	}
+	if i.tenant != nil {
+		i.applyTenant(i.tenant)
+	}
+	if i.hasTimestamps() {
+		i.applyTimestamps()
+	}
+	i.defaultScopes = nil
+	i.tenant = nil
+	i.model = nil
+
+	// Defaults can add fields (e.g. the tenant column)
+	i.defaultValues = false
+}
+
+// Returns a deep copy of the statement, which can be modified independently
+func (i *InsertUpdate) Clone() *InsertUpdate {
+	c := *i
+	c.where = i.where.Clone()
+	if i.fields != nil {
+		c.fields = make([]fieldValue, len(i.fields))
+		for j, field := range i.fields {
+			c.fields[j] = field
+			if field.from != nil {
+				c.fields[j].from = field.from.Clone()
+			}
+		}
+	}
+	if i.fromSelect != nil {
+		c.fromSelect = i.fromSelect.Clone()
+	}
+	c.conflictColumn = append([]string(nil), i.conflictColumn...)
+	c.fromColumns = append([]string(nil), i.fromColumns...)
+	c.returning = append([]string(nil), i.returning...)
+	c.joins = cloneJoins(i.joins)
+	if i.conflict != nil {
+		c.conflict = i.conflict.clone()
+	}
+	return &c
+}
+
 func (i *InsertUpdate) ToSQL() (string, []any) {
+	i.checkDefaultValues()
+	if i.hasDefaults() {
+		c := i.Clone()
+		c.applyDefaults()
+		return c.ToSQL()
+	}
+
 	query := ""
 	vars := make([]any, 0)
-	whereOffset := 0
-	for _, v := range i.fields {
-		if v.from == nil {
-			vars = append(vars, v.value)
-			whereOffset += 1
-		}
-	}
 
 	switch i.mode {
-	case insertMode:
-		if i.fromSelect != nil {
-			s, v := i.fromSelect.toSQL(len(vars))
-			query = fmt.Sprintf("INSERT INTO %s %s", i.Table, s)
-			vars = append(vars, v...)
-		} else {
-			fields := make([]string, 0)
-			values := make([]string, 0)
+	case insertMode, upsertMode:
+		columns, row, source, v := i.insertSource()
+		vars = append(vars, v...)
 
-			for j := 0; j < len(i.fields); j++ {
-				fields = append(fields, i.fields[j].key)
-				values = append(values, i.dialect.Placeholder(j))
+		switch {
+		case i.mode == insertMode:
+			keys := make([]string, 0)
+			for _, column := range columns {
+				keys = append(keys, column.key)
 			}
-			query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", i.Table, strings.Join(fields, ", "), strings.Join(values, ", "))
+			query = fmt.Sprintf("INSERT %s", insertInto(i.Table, keys, source))
+		case i.conflict == nil && i.fromSelect == nil && len(columns) > 0:
+			query = i.dialect.MakeUpsert(i.Table, i.conflictColumn, columns, []string{row})
+		default:
+			conflict := i.conflict
+			if conflict == nil {
+				conflict = OnConflict(i.conflictColumn...)
+			}
+			q, v := conflict.generate(i.Table, columns, source, len(vars), i.dialect)
+			query = q
+			vars = append(vars, v...)
 		}
 	case updateMode:
-		updates := make([]string, 0)
-		for j, field := range i.fields {
-			if field.from == nil {
-				updates = append(updates, fmt.Sprintf("%s=%s", field.key, i.dialect.Placeholder(j)))
-			} else {
-				s, v := field.from.toSQL(len(vars))
-				updates = append(updates, fmt.Sprintf("%s=(%s)", field.key, s))
-				vars = append(vars, v...)
+		query, vars = i.updateSQL()
+	default:
+		panic(fmt.Sprintf("Unknown mode: %#v", i.mode))
+	}
+
+	if len(i.returning) > 0 {
+		query = fmt.Sprintf("%s RETURNING %s", query, strings.Join(i.returning, ", "))
+	}
+
+	return query, vars
+}
+
+// Renders the rows to insert: either VALUES with a single row or a SELECT
+func (i *InsertUpdate) insertSource() ([]fieldValue, string, string, []any) {
+	if i.fromSelect == nil {
+		fields := make([]fieldValue, 0)
+		values := make([]string, 0)
+		vars := make([]any, 0)
+		for _, field := range i.fields {
+			if isDefault(field.value) && !i.dialect.SupportsDefault() {
+				continue
 			}
+			q, v := field.generate(len(vars), i.dialect)
+			fields = append(fields, field)
+			values = append(values, q)
+			vars = append(vars, v...)
+		}
+		if len(fields) == 0 {
+			return nil, "()", i.dialect.DefaultValues(), vars
 		}
+		row := fmt.Sprintf("(%s)", strings.Join(values, ", "))
+		return fields, row, fmt.Sprintf("VALUES %s", row), vars
+	}
 
-		query = fmt.Sprintf("UPDATE %s SET %s", i.Table, strings.Join(updates, ", "))
-		where, whereVars := i.where.Generate(whereOffset, i.dialect)
-		if where != "" {
-			query = fmt.Sprintf("%s WHERE %s", query, where)
+	if len(i.fromColumns) == 0 {
+		if len(i.fields) > 0 {
+			panic("Select() needs a column list to be combined with Add()")
 		}
-		vars = append(vars, whereVars...)
-	case upsertMode:
-		query = i.dialect.MakeUpsert(i.Table, i.conflictColumn, i.fields, 1)
-	default:
-		panic(fmt.Sprintf("Unknown mode: %#v", i.mode))
+		q, v := i.fromSelect.toSQL(0)
+		return nil, "", q, v
 	}
 
-	if i.returning != "" {
-		query = fmt.Sprintf("%s RETURNING %s", query, i.returning)
+	s := i.fromSelect
+	columns := make([]fieldValue, 0)
+	for _, column := range i.fromColumns {
+		columns = append(columns, fieldValue{key: column})
+	}
+	if len(i.fields) > 0 || i.mode == upsertMode {
+		s = s.Clone()
 	}
+	if len(i.fields) > 0 && len(s.Unions) > 0 {
+		panic("Can't add constant columns to a select with unions")
+	}
+	for _, field := range i.fields {
+		if field.from != nil {
+			panic("Can't add subselect columns to INSERT ... SELECT")
+		}
+		if isDefault(field.value) {
+			// Leaving out the column gives it its default value
+			continue
+		}
+		s.FieldExprs = append(s.FieldExprs, toExpression(field.value))
+		columns = append(columns, field)
+	}
+	if i.mode == upsertMode && s.Options.Where.IsEmpty() && len(s.Unions) == 0 {
+		// SQLite can't parse ON CONFLICT after a SELECT without WHERE
+		s.Where(Expr("true"))
+	}
+	q, v := s.toSQL(0)
+	return columns, "", q, v
+}
 
-	return query, vars
+func (i *InsertUpdate) updateSQL() (string, []any) {
+	b := strings.Builder{}
+	vars := make([]any, 0)
+	where := i.where
+
+	b.WriteString("UPDATE ")
+	b.WriteString(i.Table)
+	useFrom := len(i.joins) > 0 && i.dialect.UseUpdateFrom()
+	if len(i.joins) > 0 && !useFrom {
+		q, v := generateJoins(i.joins, len(vars), i.dialect)
+		b.WriteString(q)
+		vars = append(vars, v...)
+	}
+
+	updates := make([]string, 0)
+	for _, field := range i.fields {
+		q, v := field.generate(len(vars), i.dialect)
+		updates = append(updates, fmt.Sprintf("%s=%s", field.key, q))
+		vars = append(vars, v...)
+	}
+	b.WriteString(" SET ")
+	b.WriteString(strings.Join(updates, ", "))
+
+	if useFrom {
+		first := i.joins[0]
+		if first.Join != "INNER" {
+			panic(fmt.Sprintf("The first join of an update can't be a %s JOIN", first.Join))
+		}
+		b.WriteString(" FROM ")
+		b.WriteString(first.Table)
+		q, v := generateJoins(i.joins[1:], len(vars), i.dialect)
+		b.WriteString(q)
+		vars = append(vars, v...)
+		where = andWhere(first.On, where)
+	}
+
+	q, v := where.Generate(len(vars), i.dialect)
+	if q != "" {
+		b.WriteString(" WHERE ")
+		b.WriteString(q)
+	}
+	vars = append(vars, v...)
+	return b.String(), vars
 }
 
 func (i *InsertUpdate) HasClauses() bool {
@@ -1,7 +1,9 @@
 package query
 
 import (
+	"database/sql/driver"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -398,3 +400,356 @@ func TestUpsertMySQL(t *testing.T) {
 	assert.Equal(v[1], "Jack")
 	assert.Equal(v[2], 23)
 }
+
+func TestInsertUpdateClone(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	base := b.Update("customer", And(FieldEquals("isdeleted", false))).
+		Add("firstname", "Bob").
+		AddSelect("file_ref", b.Select("ref", "files s").Where(Expr("s.id=customer.file")))
+
+	c := base.Clone()
+	c.fields[0].value = "Jack"
+	c.fields[1].from.Where(IsNotNull("s.ref"))
+	c.where.children = append(c.where.children, IDEquals(4))
+
+	s, v := base.ToSQL()
+	assert.Equal("UPDATE customer SET firstname=$1, file_ref=(SELECT ref FROM files s WHERE s.id=customer.file) WHERE isdeleted=$2", s)
+	assert.Equal([]any{"Bob", false}, v)
+
+	s, v = c.ToSQL()
+	assert.Equal("UPDATE customer SET firstname=$1, file_ref=(SELECT ref FROM files s WHERE s.id=customer.file AND s.ref IS NOT NULL) WHERE (isdeleted=$2 AND id=$3)", s)
+	assert.Equal([]any{"Jack", false, 4}, v)
+}
+
+func TestUpdateSubselectNum(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, v := b.Update("cases l", IDEquals(4)).
+		Add("status", "linked").
+		AddSelect("file_ref", b.Select("ref", "files s").Where(Expr("s.id=l.file")).Where(FieldEquals("s.kind", "pdf"))).
+		Add("updated_at", Now()).
+		Add("owner", 7).
+		ToSQL()
+	assert.Equal(s, "UPDATE cases l SET status=$1, file_ref=(SELECT ref FROM files s WHERE s.id=l.file AND s.kind=$2), updated_at=CURRENT_TIMESTAMP, owner=$3 WHERE id=$4")
+	assert.Equal(v, []any{"linked", "pdf", 7, 4})
+}
+
+func TestUpdateSetExpressions(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, v := b.Update("counters", And(IDEquals(4), FieldEquals("active", true))).
+		Add("name", "home").
+		Increment("hits", 1).
+		Decrement("credits", 5).
+		SetExpr("score", "score * ? + ?", 2, 10).
+		SetDefault("state").
+		SetNull("error").
+		Add("owner", 7).
+		ToSQL()
+	assert.Equal(s, "UPDATE counters SET name=$1, hits=(hits + $2), credits=(credits - $3), score=score * $4 + $5, state=DEFAULT, error=NULL, owner=$6 WHERE (id=$7 AND active=$8)")
+	assert.Equal(v, []any{"home", 1, 5, 2, 10, 7, 4, true})
+
+	s, v = NewBuilder(MySQLDialect{}).Update("counters", IDEquals(4)).Increment("hits", Col("step")).ToSQL()
+	assert.Equal(s, "UPDATE counters SET hits=(hits + step) WHERE id=?")
+	assert.Equal(v, []any{4})
+}
+
+func TestUpdateJoin(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	pg := NewBuilder(PostgreSQLDialect{})
+	s, v := pg.Update("orders o", FieldEquals("o.status", "open")).
+		Join("customers c", And(Expr("c.id=o.customer"), FieldEquals("c.country", "be"))).
+		LeftJoin("regions r", Expr("r.id=c.region AND r.active=?", true)).
+		Add("discount", 10).
+		SetExpr("region", "r.name").
+		ToSQL()
+	assert.Equal(s, "UPDATE orders o SET discount=$1, region=r.name FROM customers c LEFT JOIN regions r ON r.id=c.region AND r.active=$2 WHERE (c.id=o.customer AND c.country=$3) AND o.status=$4")
+	assert.Equal(v, []any{10, true, "be", "open"})
+
+	s, v = NewBuilder(SqliteDialect{}).Update("orders", All()).
+		Join("customers", Expr("customers.id=orders.customer")).
+		Add("discount", 10).
+		ToSQL()
+	assert.Equal(s, "UPDATE orders SET discount=? FROM customers WHERE customers.id=orders.customer")
+	assert.Equal(v, []any{10})
+
+	my := NewBuilder(MySQLDialect{})
+	s, v = my.Update("orders o", FieldEquals("o.status", "open")).
+		Join("customers c", And(Expr("c.id=o.customer"), FieldEquals("c.country", "be"))).
+		LeftJoin("regions r", Expr("r.id=c.region")).
+		Add("discount", 10).
+		ToSQL()
+	assert.Equal(s, "UPDATE orders o INNER JOIN customers c ON (c.id=o.customer AND c.country=?) LEFT JOIN regions r ON r.id=c.region SET discount=? WHERE o.status=?")
+	assert.Equal(v, []any{"be", 10, "open"})
+
+	tenant := pg.WithTenant(Tenant{Column: "account", Value: 3})
+	s, v = tenant.Update("orders o", FieldEquals("o.status", "open")).
+		Join("customers c", Expr("c.id=o.customer")).
+		Add("discount", 10).
+		ToSQL()
+	assert.Equal(s, "UPDATE orders o SET discount=$1 FROM customers c WHERE (c.id=o.customer AND c.account=$2) AND o.status=$3 AND o.account=$4")
+	assert.Equal(v, []any{10, 3, "open", 3})
+
+	assert.Panics(func() {
+		pg.Update("orders", All()).LeftJoin("customers", Expr("customers.id=orders.customer")).Add("x", 1).ToSQL()
+	})
+	assert.Panics(func() {
+		pg.Insert("orders").Join("customers", All())
+	})
+}
+
+func TestWithFilters(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Patch struct {
+		ID        int64     `db:"id,autoincrement"`
+		Title     string    `db:"title,omitempty"`
+		Body      *string   `db:"body,omitempty"`
+		Score     int       `db:"score"`
+		Owner     int       `db:"owner"`
+		UpdatedAt time.Time `db:"updated_at,autoupdate"`
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuilder(PostgreSQLDialect{}).SetClock(func() any {
+		return now
+	})
+
+	body := ""
+	patch := Patch{ID: 3, Body: &body}
+	s, v := b.Update("documents", IDEquals(3)).With(patch, WithExclude("owner")).ToSQL()
+	assert.Equal(s, "UPDATE documents SET body=$1, score=$2, updated_at=$3 WHERE id=$4")
+	assert.Equal(v, []any{&body, 0, now, 3})
+
+	patch = Patch{Title: "Hello", Score: 5, Owner: 2}
+	s, v = b.Update("documents", IDEquals(3)).With(patch, WithOnly("title", "owner"), WithExclude("owner")).ToSQL()
+	assert.Equal(s, "UPDATE documents SET title=$1, updated_at=$2 WHERE id=$3")
+	assert.Equal(v, []any{"Hello", now, 3})
+
+	s, v = b.Insert("documents").With(patch, WithOnly("score"), WithExclude("updated_at")).ToSQL()
+	assert.Equal(s, "INSERT INTO documents (score) VALUES ($1)")
+	assert.Equal(v, []any{5})
+}
+
+func TestWithMap(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, v := b.Update("documents", IDEquals(3)).With(map[string]any{
+		"title": "Hello",
+		"body":  nil,
+		"score": Col("score").Plus(1),
+		"owner": 2,
+	}, WithExclude("owner")).ToSQL()
+	assert.Equal(s, "UPDATE documents SET body=$1, score=(score + $2), title=$3 WHERE id=$4")
+	assert.Equal(v, []any{nil, 1, "Hello", 3})
+
+	type Column string
+	s, v = b.Insert("documents").With(map[Column]int{"b": 2, "a": 1}).ToSQL()
+	assert.Equal(s, "INSERT INTO documents (a, b) VALUES ($1, $2)")
+	assert.Equal(v, []any{1, 2})
+
+	assert.Panics(func() {
+		b.Insert("documents").With([]string{"a"})
+	})
+}
+
+func TestWithNested(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Address struct {
+		Street string `db:"street"`
+		City   string `db:"city"`
+	}
+	type Audit struct {
+		CreatedBy int `db:"created_by"`
+	}
+	type Customer struct {
+		*Audit
+		ID       int64    `db:"id,autoincrement"`
+		Name     string   `db:"name"`
+		Address  Address  `db:"address_,prefix"`
+		Billing  *Address `db:"billing_,prefix"`
+		Internal Address
+	}
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	customer := Customer{
+		Name:    "Jack",
+		Address: Address{Street: "Main St", City: "Ghent"},
+	}
+	s, v := b.Insert("customers").With(customer).ToSQL()
+	assert.Equal(s, "INSERT INTO customers (name, address_street, address_city) VALUES ($1, $2, $3)")
+	assert.Equal(v, []any{"Jack", "Main St", "Ghent"})
+
+	customer.Audit = &Audit{CreatedBy: 4}
+	customer.Billing = &Address{Street: "Side St", City: "Bruges"}
+	s, v = b.Insert("customers").With(&customer, WithExclude("address_city")).ToSQL()
+	assert.Equal(s, "INSERT INTO customers (created_by, name, address_street, billing_street, billing_city) VALUES ($1, $2, $3, $4, $5)")
+	assert.Equal(v, []any{4, "Jack", "Main St", "Side St", "Bruges"})
+
+	// An embedded struct with a column name is a single column
+	type Point struct {
+		X int `db:"x"`
+		Y int `db:"y"`
+	}
+	type Shape struct {
+		Point `db:"origin,json"`
+		Name  string `db:"name"`
+	}
+	s, v = b.Insert("shapes").With(Shape{Point: Point{X: 1, Y: 2}, Name: "dot"}).ToSQL()
+	assert.Equal(s, "INSERT INTO shapes (origin, name) VALUES ($1, $2)")
+	origin, err := v[0].(driver.Valuer).Value()
+	assert.NoError(err)
+	assert.Equal(origin, `{"X":1,"Y":2}`)
+	assert.Equal(v[1], "dot")
+}
+
+func TestInsertSelect(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	archived := b.Select("id, title", "posts").Where(FieldLessThan("created", 100))
+
+	s, v := b.Insert("archive").Select(archived, "post_id", "title").ToSQL()
+	assert.Equal(s, "INSERT INTO archive (post_id, title) SELECT id, title FROM posts WHERE created<$1")
+	assert.Equal(v, []any{100})
+
+	s, v = b.Insert("archive").
+		Select(archived, "post_id", "title").
+		Add("reason", "expired").
+		Add("archived_at", Now()).
+		Returning("id").
+		ToSQL()
+	assert.Equal(s, "INSERT INTO archive (post_id, title, reason, archived_at) SELECT id, title, $1, CURRENT_TIMESTAMP FROM posts WHERE created<$2 RETURNING id")
+	assert.Equal(v, []any{"expired", 100})
+
+	s, v = b.Insert("archive").
+		Select(archived, "post_id", "title").
+		Add("reason", "expired").
+		OnConflict(OnConflict("post_id").Exclude("post_id").Where(FieldNotEquals("archive.locked", true))).
+		Returning("id", "post_id").
+		ToSQL()
+	assert.Equal(s, "INSERT INTO archive (post_id, title, reason) SELECT id, title, $1 FROM posts WHERE created<$2 ON CONFLICT (post_id) DO UPDATE SET title=EXCLUDED.title, reason=EXCLUDED.reason WHERE archive.locked!=$3 RETURNING id, post_id")
+	assert.Equal(v, []any{"expired", 100, true})
+
+	s, v = NewBuilder(SqliteDialect{}).Upsert("archive", "post_id").Select(b.Select("id", "posts"), "post_id").ToSQL()
+	assert.Equal(s, "INSERT INTO archive (post_id) SELECT id FROM posts WHERE true ON CONFLICT (post_id) DO UPDATE SET post_id=EXCLUDED.post_id")
+	assert.Equal(v, []any{})
+
+	// The archived select isn't modified
+	s, _ = archived.ToSQL()
+	assert.Equal(s, "SELECT id, title FROM posts WHERE created<$1")
+
+	tenant := b.WithTenant(Tenant{Column: "account", Value: 3})
+	s, v = tenant.Insert("archive").Select(tenant.Select("id", "posts"), "post_id").ToSQL()
+	assert.Equal(s, "INSERT INTO archive (post_id, account) SELECT id, $1 FROM posts WHERE posts.account=$2")
+	assert.Equal(v, []any{3, 3})
+
+	// A selected tenant column is replaced by the tenant
+	s, v = tenant.Insert("archive").Select(b.Select("id, account, title", "posts"), "post_id", "account", "title").ToSQL()
+	assert.Equal(s, "INSERT INTO archive (post_id, account, title) SELECT id, $1, title FROM posts")
+	assert.Equal(v, []any{3})
+
+	s, v = tenant.Insert("archive").
+		Select(b.Select("", "posts").AddFields(Col("id"), Param(4)).Where(FieldEquals("kind", "blog")), "post_id", "account").
+		Add("reason", "expired").
+		ToSQL()
+	assert.Equal(s, "INSERT INTO archive (post_id, account, reason) SELECT id, $1, $2 FROM posts WHERE kind=$3")
+	assert.Equal(v, []any{3, "expired", "blog"})
+
+	assert.Panics(func() {
+		tenant.Insert("archive").Select(b.Select("*", "posts")).ToSQL()
+	})
+	assert.Panics(func() {
+		tenant.Insert("archive").Select(b.Select("*", "posts"), "post_id", "account").ToSQL()
+	})
+
+	assert.Panics(func() {
+		b.Insert("archive").Select(archived).Add("reason", "expired").ToSQL()
+	})
+}
+
+func TestInsertDefault(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	pg := NewBuilder(PostgreSQLDialect{})
+	my := NewBuilder(MySQLDialect{})
+	sqlite := NewBuilder(SqliteDialect{})
+
+	s, v := pg.Insert("events").DefaultValues().Returning("id").ToSQL()
+	assert.Equal(s, "INSERT INTO events DEFAULT VALUES RETURNING id")
+	assert.Equal(v, []any{})
+
+	s, _ = sqlite.Insert("events").ToSQL()
+	assert.Equal(s, "INSERT INTO events DEFAULT VALUES")
+
+	s, _ = my.Insert("events").DefaultValues().ToSQL()
+	assert.Equal(s, "INSERT INTO events () VALUES ()")
+
+	s, _ = pg.Upsert("events").ToSQL()
+	assert.Equal(s, "INSERT INTO events DEFAULT VALUES ON CONFLICT DO NOTHING")
+
+	s, v = pg.Insert("events").Add("kind", Default).Add("name", "signup").SetDefault("created").ToSQL()
+	assert.Equal(s, "INSERT INTO events (kind, name, created) VALUES (DEFAULT, $1, DEFAULT)")
+	assert.Equal(v, []any{"signup"})
+
+	s, v = sqlite.Insert("events").Add("kind", Default).Add("name", "signup").ToSQL()
+	assert.Equal(s, "INSERT INTO events (name) VALUES (?)")
+	assert.Equal(v, []any{"signup"})
+
+	s, _ = sqlite.Insert("events").Add("kind", Default).ToSQL()
+	assert.Equal(s, "INSERT INTO events DEFAULT VALUES")
+
+	s, v = my.Update("events", IDEquals(1)).SetDefault("kind").ToSQL()
+	assert.Equal(s, "UPDATE events SET kind=DEFAULT WHERE id=?")
+	assert.Equal(v, []any{1})
+
+	s, v = pg.Insert("archive").Select(pg.Select("id", "events"), "event_id").Add("kind", Default).ToSQL()
+	assert.Equal(s, "INSERT INTO archive (event_id) SELECT id FROM events")
+	assert.Equal(v, []any{})
+
+	assert.Panics(func() {
+		sqlite.Update("events", IDEquals(1)).SetDefault("kind").ToSQL()
+	})
+	assert.Panics(func() {
+		pg.Insert("events").Add("name", "signup").DefaultValues()
+	})
+	assert.Panics(func() {
+		pg.Insert("events").DefaultValues().Add("name", "signup").ToSQL()
+	})
+
+	// Defaults of the builder can still add columns
+	s, v = pg.WithTenant(Tenant{Column: "account", Value: 3}).Insert("events").DefaultValues().ToSQL()
+	assert.Equal(s, "INSERT INTO events (account) VALUES ($1)")
+	assert.Equal(v, []any{3})
+}
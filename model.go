@@ -0,0 +1,161 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+)
+
+// A struct field that is mapped onto a column through its db tag
+type structField struct {
+	column  string
+	options []string
+	value   reflect.Value
+}
+
+func (f structField) hasOption(option string) bool {
+	for _, o := range f.options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Calls fn for each field with a db tag, including those of embedded structs
+// and of nested structs tagged with the prefix option (e.g.
+// `db:"address_,prefix"`). Fields of nil struct pointers are skipped.
+//
+// An embedded struct with a column name in its tag is a single column.
+func structFields(t reflect.Type, v reflect.Value, fn func(f structField)) {
+	prefixedStructFields("", t, v, false, fn)
+}
+
+// Like structFields, but allocates nil struct pointers so all fields can be
+// written to
+func allocStructFields(v reflect.Value, fn func(f structField)) {
+	prefixedStructFields("", v.Type(), v, true, fn)
+}
+
+// Like structFields, but walks the type: fields of nil struct pointers are
+// included and have no value
+func typeFields(t reflect.Type, fn func(f structField)) {
+	prefixedStructFields("", t, reflect.Value{}, false, fn)
+}
+
+func prefixedStructFields(prefix string, t reflect.Type, v reflect.Value, alloc bool, fn func(f structField)) {
+	for j := 0; j < t.NumField(); j++ {
+		field := t.Field(j)
+		tag := field.Tag.Get("db")
+		parts := strings.Split(tag, ",")
+		options := parts[1:]
+
+		nested := field.Anonymous && parts[0] == ""
+		if parts[0] != "" && parts[0] != "-" {
+			for _, o := range options {
+				nested = nested || o == "prefix"
+			}
+		}
+		if nested {
+			ft, fv := field.Type, reflect.Value{}
+			if v.IsValid() {
+				fv = v.Field(j)
+			}
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+				if fv.IsValid() && fv.IsNil() {
+					if !alloc {
+						continue
+					}
+					fv.Set(reflect.New(ft))
+				}
+				if fv.IsValid() {
+					fv = fv.Elem()
+				}
+			}
+			if ft.Kind() == reflect.Struct {
+				prefixedStructFields(prefix+parts[0], ft, fv, alloc, fn)
+			}
+			continue
+		}
+
+		if parts[0] == "" || parts[0] == "-" {
+			continue
+		}
+		f := structField{
+			column:  prefix + parts[0],
+			options: options,
+		}
+		if v.IsValid() {
+			f.value = v.Field(j)
+		}
+		fn(f)
+	}
+}
+
+// Table metadata derived from the db tags of a struct, see Builder.Register
+type model struct {
+	softDelete string
+	autoCreate []string
+	autoUpdate []string
+	codecs     map[string]Codec
+}
+
+func (m *model) isAutoCreate(column string) bool {
+	for _, c := range m.autoCreate {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Registers the struct type stored in a table, this enables the tag options
+// that affect statements without a struct value:
+//
+//   - softdelete: Delete sets the column to the current time (see
+//     Builder.SetClock) instead of removing the row, Select skips rows where
+//     it is set (also in joined tables).
+//   - autocreate / autoupdate: set by inserts (and updates for autoupdate)
+//     that don't set them explicitly, see Builder.SetClock.
+//   - codecs (e.g. json): BulkInsert encodes the values of the column.
+//
+// Register tables before using the builder, this is not safe for concurrent
+// use.
+func (b *Builder) Register(table string, obj any) *Builder {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	m := &model{}
+	typeFields(t, func(f structField) {
+		if f.hasOption("softdelete") {
+			m.softDelete = f.column
+		}
+		if f.hasOption("autocreate") {
+			m.autoCreate = append(m.autoCreate, f.column)
+		}
+		if f.hasOption("autoupdate") {
+			m.autoUpdate = append(m.autoUpdate, f.column)
+		}
+		if c := f.codec(); c != nil {
+			if m.codecs == nil {
+				m.codecs = make(map[string]Codec)
+			}
+			m.codecs[f.column] = c
+		}
+	})
+
+	if b.models == nil {
+		b.models = make(map[string]*model)
+	}
+	b.models[table] = m
+	return b
+}
+
+func (b *Builder) model(table string) *model {
+	if m, ok := b.models[tableName(table)]; ok {
+		return m
+	}
+	return &model{}
+}
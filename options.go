@@ -1,16 +1,25 @@
 package query
 
+// Can be used as Limit or Offset to clear it when combining options
+const NoLimit = -1
+
 type Options struct {
 	Where   Where
 	Limit   int64
 	Offset  int64
-	GroupBy string
-	OrderBy []string
+	GroupBy []Group
+	OrderBy []Order
 	Having  Where
 
+	// Bound to the placeholders of raw Expr clauses in Where that don't carry
+	// their own arguments, in order. Any remaining arguments are bound after
+	// the Where clause.
 	Args []any
 }
 
+// Merges the given options into o, replacing the Where and Having clauses.
+//
+// Use Combine to layer options on top of each other.
 func (o *Options) Merge(opts *Options) *Options {
 	if opts != nil {
 		if !opts.Where.IsEmpty() {
@@ -22,6 +31,9 @@ func (o *Options) Merge(opts *Options) *Options {
 		if opts.Offset > 0 {
 			o.Offset = opts.Offset
 		}
+		if len(opts.GroupBy) > 0 {
+			o.GroupBy = append(o.GroupBy, opts.GroupBy...)
+		}
 		if len(opts.OrderBy) > 0 {
 			o.OrderBy = append(o.OrderBy, opts.OrderBy...)
 		}
@@ -33,6 +45,80 @@ func (o *Options) Merge(opts *Options) *Options {
 	return o
 }
 
+// Returns a new set of options with each of opts layered on top of o:
+//
+//   - Where and Having clauses are ANDed together
+//   - GroupBy, OrderBy and Args are appended
+//   - Limit and Offset are overridden when non-zero, use NoLimit to clear them
+//
+// Neither o nor any of opts is modified.
+func (o *Options) Combine(opts ...*Options) *Options {
+	c := o.Clone()
+	c.Where, c.Args = bindArgs(c.Where, c.Args)
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt = opt.Clone()
+		opt.Where, opt.Args = bindArgs(opt.Where, opt.Args)
+
+		c.Where = andWhere(c.Where, opt.Where)
+		c.Having = andWhere(c.Having, opt.Having)
+		if opt.Limit != 0 {
+			c.Limit = opt.Limit
+		}
+		if opt.Offset != 0 {
+			c.Offset = opt.Offset
+		}
+		c.GroupBy = append(c.GroupBy, opt.GroupBy...)
+		c.OrderBy = append(c.OrderBy, opt.OrderBy...)
+		c.Args = append(c.Args, opt.Args...)
+	}
+	if c.Limit < 0 {
+		c.Limit = 0
+	}
+	if c.Offset < 0 {
+		c.Offset = 0
+	}
+	return c
+}
+
+// Moves args into the raw Expr clauses of where that have placeholders but no
+// arguments, so they're numbered where they appear. Returns the unused args.
+func bindArgs(where Where, args []any) (Where, []any) {
+	if len(args) == 0 {
+		return where, args
+	}
+	switch where.mode {
+	case andClause, orClause:
+		children := make([]Where, len(where.children))
+		for j, child := range where.children {
+			children[j], args = bindArgs(child, args)
+		}
+		where.children = children
+	case exprClause:
+		n := countPlaceholders(where.field)
+		if len(where.values) == 0 && n > 0 && n <= len(args) {
+			where.values = append([]any(nil), args[:n]...)
+			args = args[n:]
+		}
+	}
+	return where, args
+}
+
+// Returns a deep copy of the options
+func (o *Options) Clone() *Options {
+	return &Options{
+		Where:   o.Where.Clone(),
+		Limit:   o.Limit,
+		Offset:  o.Offset,
+		GroupBy: cloneGroups(o.GroupBy),
+		OrderBy: append([]Order(nil), o.OrderBy...),
+		Having:  o.Having.Clone(),
+		Args:    cloneArgs(o.Args),
+	}
+}
+
 // Helper that only selects a single ID
 func WhereID(v any) *Options {
 	return &Options{
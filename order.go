@@ -0,0 +1,140 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type NullsOrder int
+
+const (
+	NullsDefault NullsOrder = iota
+	NullsFirst
+	NullsLast
+)
+
+var ErrInvalidSortKey = errors.New("Invalid sort key")
+
+// A single element of an ORDER BY clause
+type Order struct {
+	Field string
+	Desc  bool
+	Nulls NullsOrder
+
+	expr *Expression
+}
+
+func Asc(field string) Order {
+	return Order{
+		Field: field,
+	}
+}
+
+func Desc(field string) Order {
+	return Order{
+		Field: field,
+		Desc:  true,
+	}
+}
+
+func (o Order) NullsFirst() Order {
+	o.Nulls = NullsFirst
+	return o
+}
+
+func (o Order) NullsLast() Order {
+	o.Nulls = NullsLast
+	return o
+}
+
+func (o Order) field(offset int, dialect Dialect) (string, []any) {
+	if o.expr != nil {
+		return o.expr.Generate(offset, dialect)
+	}
+	return o.Field, nil
+}
+
+func (o Order) generate(offset int, dialect Dialect) (string, []any) {
+	nulls := ""
+	switch o.Nulls {
+	case NullsDefault:
+	case NullsFirst:
+		nulls = "FIRST"
+	case NullsLast:
+		nulls = "LAST"
+	default:
+		panic(fmt.Sprintf("Unknown nulls order %#v", o.Nulls))
+	}
+
+	parts := make([]string, 0)
+	vars := make([]any, 0)
+	if nulls != "" && !dialect.SupportsNullsOrder() {
+		// Emulated by sorting on whether the value is NULL first
+		q, v := o.field(offset, dialect)
+		if o.Nulls == NullsFirst {
+			q = fmt.Sprintf("%s IS NULL DESC", q)
+		} else {
+			q = fmt.Sprintf("%s IS NULL", q)
+		}
+		parts = append(parts, q)
+		vars = append(vars, v...)
+		nulls = ""
+	}
+
+	q, v := o.field(offset+len(vars), dialect)
+	if o.Desc {
+		q = fmt.Sprintf("%s DESC", q)
+	}
+	if nulls != "" {
+		q = fmt.Sprintf("%s NULLS %s", q, nulls)
+	}
+	parts = append(parts, q)
+	vars = append(vars, v...)
+	return strings.Join(parts, ", "), vars
+}
+
+func generateOrders(orders []Order, offset int, dialect Dialect) (string, []any) {
+	parts := make([]string, 0)
+	vars := make([]any, 0)
+	for _, o := range orders {
+		q, v := o.generate(offset+len(vars), dialect)
+		parts = append(parts, q)
+		vars = append(vars, v...)
+	}
+	return strings.Join(parts, ", "), vars
+}
+
+// Maps user-supplied sort keys onto the columns they sort on. Keys that are
+// not in the map are rejected, so it is safe to use with request parameters.
+type SortKeys map[string]string
+
+// Parses a comma-separated list of sort keys, prefix a key with - to sort
+// descending (e.g. "name,-created").
+func (k SortKeys) Parse(input string) ([]Order, error) {
+	orders := make([]Order, 0)
+	for _, key := range strings.Split(input, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		desc := false
+		if strings.HasPrefix(key, "-") {
+			desc = true
+			key = key[1:]
+		} else if strings.HasPrefix(key, "+") {
+			key = key[1:]
+		}
+
+		field, ok := k[key]
+		if !ok || field == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSortKey, key)
+		}
+		orders = append(orders, Order{
+			Field: field,
+			Desc:  desc,
+		})
+	}
+	return orders, nil
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrder(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, v := b.Select("*", "contacts").Order(Asc("name"), Desc("created")).ToSQL()
+	assert.Equal(s, "SELECT * FROM contacts ORDER BY name, created DESC")
+	assert.Equal(len(v), 0)
+
+	s, v = b.Select("*", "contacts").Order(Asc("name").NullsFirst(), Desc("created").NullsLast()).ToSQL()
+	assert.Equal(s, "SELECT * FROM contacts ORDER BY name NULLS FIRST, created DESC NULLS LAST")
+	assert.Equal(len(v), 0)
+
+	s, v = b.Select("*", "contacts").OrderBy("name").OrderByDir("created", true).ToSQL()
+	assert.Equal(s, "SELECT * FROM contacts ORDER BY name, created DESC")
+	assert.Equal(len(v), 0)
+}
+
+func TestOrderNullsMySQL(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(MySQLDialect{})
+
+	s, v := b.Select("*", "contacts").Order(Asc("name").NullsLast()).ToSQL()
+	assert.Equal(s, "SELECT * FROM contacts ORDER BY name IS NULL, name")
+	assert.Equal(len(v), 0)
+
+	s, v = b.Select("*", "contacts").Order(Desc("name").NullsFirst(), Asc("id")).ToSQL()
+	assert.Equal(s, "SELECT * FROM contacts ORDER BY name IS NULL DESC, name DESC, id")
+	assert.Equal(len(v), 0)
+}
+
+func TestSortKeys(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	keys := SortKeys{
+		"name":    "c.name",
+		"created": "c.created_at",
+	}
+
+	orders, err := keys.Parse("name, -created")
+	assert.NoError(err)
+	assert.Equal(orders, []Order{Asc("c.name"), Desc("c.created_at")})
+
+	s, _ := NewBuilder(PostgreSQLDialect{}).Select("*", "contacts c").Order(orders...).ToSQL()
+	assert.Equal(s, "SELECT * FROM contacts c ORDER BY c.name, c.created_at DESC")
+
+	orders, err = keys.Parse("")
+	assert.NoError(err)
+	assert.Len(orders, 0)
+
+	_, err = keys.Parse("name,password")
+	assert.True(errors.Is(err, ErrInvalidSortKey))
+
+	_, err = keys.Parse("name; DROP TABLE contacts")
+	assert.True(errors.Is(err, ErrInvalidSortKey))
+}
@@ -0,0 +1,127 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// Returns the struct that ReturningStruct writes into
+func returningTarget(obj any) reflect.Value {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Need a pointer to a struct, got %T", obj))
+	}
+	return v.Elem()
+}
+
+// The columns of a struct type, in field order
+func structColumns(t reflect.Type) []string {
+	columns := make([]string, 0)
+	typeFields(t, func(f structField) {
+		columns = append(columns, f.column)
+	})
+	return columns
+}
+
+// Scans the current row into the fields of a struct by column name, unknown
+// columns are ignored.
+func scanStruct(rows *sql.Rows, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	fields := make(map[string]any)
+	allocStructFields(v, func(f structField) {
+		fields[f.column] = f.scanTarget()
+	})
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	dest := make([]any, len(columns))
+	for j, column := range columns {
+		if field, ok := fields[column]; ok {
+			dest[j] = field
+		} else {
+			dest[j] = new(any)
+		}
+	}
+	return rows.Scan(dest...)
+}
+
+// Scans the current row into a pointer to a struct, matching columns by the db
+// tags of its fields. Codec tag options (e.g. json) are decoded.
+func ScanStruct(rows *sql.Rows, obj any) error {
+	return scanStruct(rows, returningTarget(obj))
+}
+
+// Executes the statement and writes the returned row into the struct passed
+// to ReturningStruct.
+//
+// Returns sql.ErrNoRows when no row was affected, ErrStaleObject for an
+// update of an object with a version column or ErrNoChanges (see Exec).
+func (i *InsertUpdate) Scan(ctx context.Context, db Queryer) error {
+	if !i.returnInto.IsValid() {
+		panic("Scan() needs ReturningStruct()")
+	}
+	if i.isUnchanged() {
+		return ErrNoChanges
+	}
+
+	query, args := i.ToSQL()
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if i.versioned {
+			return ErrStaleObject
+		}
+		return sql.ErrNoRows
+	}
+	if err := scanStruct(rows, i.returnInto); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// Executes the statement and writes the returned rows into the elements of
+// the slice passed to ReturningStruct. Returns the number of returned rows.
+func (i *BulkInsert) Scan(ctx context.Context, db Queryer) (int, error) {
+	if !i.returnInto.IsValid() {
+		panic("Scan() needs ReturningStruct()")
+	}
+
+	query, args := i.ToSQL()
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		if n >= i.returnInto.Len() {
+			return n, fmt.Errorf("Got more rows than the %d elements of the slice", i.returnInto.Len())
+		}
+		if err := scanStruct(rows, i.returnInto.Index(n)); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, err
+	}
+	return n, rows.Close()
+}
@@ -0,0 +1,196 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A database that answers every query with the same rows
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	queries []string
+}
+
+func (f *fakeRows) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeRowsConn{f}, nil
+}
+
+func (f *fakeRows) Driver() driver.Driver {
+	return nil
+}
+
+type fakeRowsConn struct {
+	db *fakeRows
+}
+
+func (c *fakeRowsConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("Not supported")
+}
+
+func (c *fakeRowsConn) Close() error {
+	return nil
+}
+
+func (c *fakeRowsConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("Not supported")
+}
+
+func (c *fakeRowsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.queries = append(c.db.queries, query)
+	return &fakeRowsResult{columns: c.db.columns, rows: c.db.rows}, nil
+}
+
+type fakeRowsResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRowsResult) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRowsResult) Close() error {
+	return nil
+}
+
+func (r *fakeRowsResult) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestReturning(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Post struct {
+		ID        int64     `db:"id,autoincrement"`
+		Title     string    `db:"title"`
+		CreatedAt time.Time `db:"created_at,readonly"`
+	}
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, _ := b.Update("posts", IDEquals(1)).Add("title", "Hello").Returning("id", "title").Returning("created_at").ToSQL()
+	assert.Equal(s, "UPDATE posts SET title=$1 WHERE id=$2 RETURNING id, title, created_at")
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeRows{
+		columns: []string{"id", "title", "created_at"},
+		rows:    [][]driver.Value{{int64(7), "Hello", created}},
+	}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	post := &Post{Title: "Hello"}
+	err := b.Insert("posts").With(post).ReturningStruct(post).Scan(context.Background(), db)
+	assert.NoError(err)
+	assert.Equal(fake.queries, []string{"INSERT INTO posts (title) VALUES ($1) RETURNING id, title, created_at"})
+	assert.Equal(post, &Post{ID: 7, Title: "Hello", CreatedAt: created})
+
+	fake.rows = nil
+	err = b.Upsert("posts").With(post).ReturningStruct(post).Scan(context.Background(), db)
+	assert.True(errors.Is(err, sql.ErrNoRows))
+
+	assert.Panics(func() {
+		b.Insert("posts").ReturningStruct(Post{})
+	})
+}
+
+func TestReturningNested(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Audit struct {
+		CreatedAt time.Time  `db:"created_at,autocreate"`
+		DeletedAt *time.Time `db:"deleted_at,softdelete"`
+	}
+	type Address struct {
+		City string `db:"city"`
+	}
+	type Post struct {
+		*Audit
+		ID      int64    `db:"id,autoincrement"`
+		Address *Address `db:"address_,prefix"`
+	}
+
+	// Tags inside nil pointers are found through the type
+	b := NewBuilder(PostgreSQLDialect{}).Register("posts", Post{})
+	s, _ := b.Select("id", "posts").ToSQL()
+	assert.Equal(s, "SELECT id FROM posts WHERE posts.deleted_at IS NULL")
+	assert.Equal(structColumns(reflect.TypeOf(Post{})), []string{"created_at", "deleted_at", "id", "address_city"})
+
+	// Scanning allocates nil pointers
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeRows{
+		columns: []string{"created_at", "deleted_at", "id", "address_city"},
+		rows:    [][]driver.Value{{created, nil, int64(7), "Ghent"}},
+	}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	post := &Post{}
+	err := b.Insert("posts").With(post).ReturningStruct(post).Scan(context.Background(), db)
+	assert.NoError(err)
+	assert.Equal(fake.queries, []string{"INSERT INTO posts (created_at) VALUES ($1) RETURNING created_at, deleted_at, id, address_city"})
+	assert.Equal(post, &Post{
+		Audit:   &Audit{CreatedAt: created},
+		ID:      7,
+		Address: &Address{City: "Ghent"},
+	})
+}
+
+func TestBulkInsertReturning(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Post struct {
+		ID    int64  `db:"id"`
+		Title string `db:"title"`
+	}
+
+	b := NewBuilder(SqliteDialect{})
+
+	posts := []*Post{{Title: "Hello"}, {Title: "World"}}
+	insert := b.BulkInsert("posts", []string{"title"}).ReturningStruct(posts)
+	for _, post := range posts {
+		assert.NoError(insert.Add(post.Title))
+	}
+
+	s, v := insert.ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title) VALUES (?), (?) RETURNING id, title")
+	assert.Equal(v, []any{"Hello", "World"})
+
+	fake := &fakeRows{
+		columns: []string{"id", "title"},
+		rows:    [][]driver.Value{{int64(1), "Hello"}, {int64(2), "World"}},
+	}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	n, err := insert.Scan(context.Background(), db)
+	assert.NoError(err)
+	assert.Equal(n, 2)
+	assert.Equal(posts, []*Post{{ID: 1, Title: "Hello"}, {ID: 2, Title: "World"}})
+
+	values := make([]Post, 1)
+	fake.rows = [][]driver.Value{{int64(1), "Hello"}, {int64(2), "World"}}
+	_, err = b.BulkInsert("posts", []string{"title"}).ReturningStruct(values).Scan(context.Background(), db)
+	assert.Error(err)
+	assert.Equal(values[0], Post{ID: 1, Title: "Hello"})
+}
@@ -0,0 +1,56 @@
+package query
+
+import "strings"
+
+// A reusable query fragment, such as "not deleted" or "ordered by newest".
+//
+// Each function is applied to the matching kind of statement, statements
+// without a function are left untouched.
+type Scope struct {
+	Select     func(s *Select)
+	Insert     func(i *InsertUpdate) // Also used for upserts
+	Update     func(i *InsertUpdate)
+	Delete     func(d *Delete)
+	BulkInsert func(i *BulkInsert)
+}
+
+// Scope that ANDs the given clause into selects, updates and deletes
+func WhereScope(where Where) Scope {
+	return Scope{
+		Select: func(s *Select) {
+			s.Where(where)
+		},
+		Update: func(i *InsertUpdate) {
+			i.Where(where)
+		},
+		Delete: func(d *Delete) {
+			d.Where(where)
+		},
+	}
+}
+
+// Registers scopes that are applied to every statement on the given table
+// when it is rendered, unless Unscoped() is called on it.
+//
+// Register default scopes before using the builder, this is not safe for
+// concurrent use.
+func (b *Builder) DefaultScopes(table string, scopes ...Scope) *Builder {
+	if b.scopes == nil {
+		b.scopes = make(map[string][]Scope)
+	}
+	b.scopes[table] = append(b.scopes[table], scopes...)
+	return b
+}
+
+func (b *Builder) defaultScopes(table string) []Scope {
+	return b.scopes[tableName(table)]
+}
+
+// Strips the alias from a table expression (e.g. "contacts c")
+func tableName(table string) string {
+	fields := strings.Fields(table)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
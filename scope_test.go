@@ -0,0 +1,107 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopes(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	notDeleted := WhereScope(IsNull("deleted_at"))
+	newest := Scope{
+		Select: func(s *Select) {
+			s.OrderByDesc("created_at")
+		},
+	}
+	owner := func(id int) Scope {
+		return Scope{
+			Select: func(s *Select) {
+				s.Where(FieldEquals("owner", id))
+			},
+			Update: func(i *InsertUpdate) {
+				i.Where(FieldEquals("owner", id))
+			},
+			Insert: func(i *InsertUpdate) {
+				i.Add("owner", id)
+			},
+		}
+	}
+
+	s, v := b.Select("*", "posts").Where(FieldEquals("published", true)).Scopes(notDeleted, newest, owner(3)).ToSQL()
+	assert.Equal(s, "SELECT * FROM posts WHERE published=$1 AND deleted_at IS NULL AND owner=$2 ORDER BY created_at DESC")
+	assert.Equal(v, []any{true, 3})
+
+	s, v = b.Update("posts", IDEquals(4)).Add("title", "Hello").Scopes(notDeleted, newest, owner(3)).ToSQL()
+	assert.Equal(s, "UPDATE posts SET title=$1 WHERE id=$2 AND deleted_at IS NULL AND owner=$3")
+	assert.Equal(v, []any{"Hello", 4, 3})
+
+	s, v = b.Update("posts", And(IDEquals(4), FieldEquals("published", true))).Add("title", "Hello").Scopes(owner(3)).ToSQL()
+	assert.Equal(s, "UPDATE posts SET title=$1 WHERE (id=$2 AND published=$3) AND owner=$4")
+	assert.Equal(v, []any{"Hello", 4, true, 3})
+
+	s, v = b.Insert("posts").Add("title", "Hello").Scopes(notDeleted, owner(3)).ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, owner) VALUES ($1, $2)")
+	assert.Equal(v, []any{"Hello", 3})
+
+	s, v = b.Delete("posts", IDEquals(4)).Scopes(notDeleted, newest).ToSQL()
+	assert.Equal(s, "DELETE FROM posts WHERE id=$1 AND deleted_at IS NULL")
+	assert.Equal(v, []any{4})
+}
+
+func TestDefaultScopes(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{}).
+		DefaultScopes("posts", WhereScope(IsNull("deleted_at"))).
+		DefaultScopes("posts", Scope{
+			BulkInsert: func(i *BulkInsert) {
+				i.Columns = append(i.Columns, "source")
+				for j := range i.Values {
+					i.Values[j] = append(i.Values[j], "import")
+				}
+			},
+		})
+
+	base := b.Select("*", "posts p").Where(FieldEquals("p.published", true))
+
+	s, v := base.ToSQL()
+	assert.Equal(s, "SELECT * FROM posts p WHERE p.published=$1 AND deleted_at IS NULL")
+	assert.Equal(v, []any{true})
+
+	// Rendering does not modify the query itself
+	s, v = base.ToSQL()
+	assert.Equal(s, "SELECT * FROM posts p WHERE p.published=$1 AND deleted_at IS NULL")
+	assert.Equal(v, []any{true})
+
+	s, v = base.Unscoped().ToSQL()
+	assert.Equal(s, "SELECT * FROM posts p WHERE p.published=$1")
+	assert.Equal(v, []any{true})
+
+	s, v = b.Select("*", "users").Where(In("id", b.Select("author", "posts"))).ToSQL()
+	assert.Equal(s, "SELECT * FROM users WHERE id IN (SELECT author FROM posts WHERE deleted_at IS NULL)")
+	assert.Len(v, 0)
+
+	s, v = b.Update("posts", IDEquals(4)).Add("title", "Hello").ToSQL()
+	assert.Equal(s, "UPDATE posts SET title=$1 WHERE id=$2 AND deleted_at IS NULL")
+	assert.Equal(v, []any{"Hello", 4})
+
+	s, v = b.Delete("posts", IDEquals(4)).Unscoped().ToSQL()
+	assert.Equal(s, "DELETE FROM posts WHERE id=$1")
+	assert.Equal(v, []any{4})
+
+	insert := b.BulkInsert("posts", []string{"title"})
+	assert.NoError(insert.Add("Hello"))
+	assert.NoError(insert.Add("World"))
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, source) VALUES ($1, $2), ($3, $4)")
+	assert.Equal(v, []any{"Hello", "import", "World", "import"})
+	assert.Equal(insert.Columns, []string{"title"})
+}
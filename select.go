@@ -10,11 +10,22 @@ type Select struct {
 	Dialect Dialect
 	Fields  string
 	Table   string
+
+	// Rendered after Fields, their parameters are numbered after Args
+	FieldExprs []Expression
+
 	Options Options
 	Joins   []Join
 	Unions  []*Select
 	CTEs    []With
 	Args    []any
+
+	defaultScopes []Scope
+	unscoped      bool
+	tenant        *Tenant
+	softDelete    string
+	deleted       deletedFilter
+	models        map[string]*model
 }
 
 type With struct {
@@ -28,6 +39,44 @@ type Join struct {
 	On    Where
 }
 
+func cloneJoins(joins []Join) []Join {
+	if joins == nil {
+		return nil
+	}
+	c := make([]Join, len(joins))
+	for i, j := range joins {
+		c[i] = Join{
+			Join:  j.Join,
+			Table: j.Table,
+			On:    j.On.Clone(),
+		}
+	}
+	return c
+}
+
+// Renders the joins, each prefixed with a space
+func generateJoins(joins []Join, offset int, dialect Dialect) (string, []any) {
+	b := strings.Builder{}
+	vars := make([]any, 0)
+	for _, join := range joins {
+		b.WriteString(" ")
+		b.WriteString(join.Join)
+		b.WriteString(" JOIN ")
+		b.WriteString(join.Table)
+		b.WriteString(" ON ")
+		q, v := join.On.Generate(offset+len(vars), dialect)
+		b.WriteString(q)
+		vars = append(vars, v...)
+	}
+	return b.String(), vars
+}
+
+// Adds expressions to the selected fields
+func (s *Select) AddFields(exprs ...Expression) *Select {
+	s.FieldExprs = append(s.FieldExprs, exprs...)
+	return s
+}
+
 func (s *Select) Where(where Where) *Select {
 	if s.Options.Where.IsEmpty() {
 		s.Options.Where = And()
@@ -52,8 +101,25 @@ func (s *Select) Having(where Where) *Select {
 	return s
 }
 
-func (s *Select) GroupBy(field string) *Select {
-	s.Options.GroupBy = field
+// Layers the given options on top of the current ones, see Options.Combine
+func (s *Select) Apply(opts ...*Options) *Select {
+	s.Options = *s.Options.Combine(opts...)
+	return s
+}
+
+func (s *Select) GroupBy(fields ...string) *Select {
+	for _, field := range fields {
+		s.Options.GroupBy = append(s.Options.GroupBy, GroupField(field))
+	}
+	return s
+}
+
+func (s *Select) GroupByExpr(expr string, args ...any) *Select {
+	return s.Group(GroupExpr(expr, args...))
+}
+
+func (s *Select) Group(groups ...Group) *Select {
+	s.Options.GroupBy = append(s.Options.GroupBy, groups...)
 	return s
 }
 
@@ -68,12 +134,14 @@ func (s *Select) Offset(offset int64) *Select {
 }
 
 func (s *Select) OrderBy(fields ...string) *Select {
-	s.Options.OrderBy = append(s.Options.OrderBy, fields...)
+	for _, field := range fields {
+		s.Options.OrderBy = append(s.Options.OrderBy, Asc(field))
+	}
 	return s
 }
 
 func (s *Select) OrderByDesc(field string) *Select {
-	return s.OrderBy(fmt.Sprintf("%s DESC", field))
+	return s.Order(Desc(field))
 }
 
 func (s *Select) OrderByDir(field string, desc bool) *Select {
@@ -84,6 +152,11 @@ func (s *Select) OrderByDir(field string, desc bool) *Select {
 	}
 }
 
+func (s *Select) Order(orders ...Order) *Select {
+	s.Options.OrderBy = append(s.Options.OrderBy, orders...)
+	return s
+}
+
 func (s *Select) Join(table string, on Where) *Select {
 	s.Joins = append(s.Joins, Join{
 		Join:  "INNER",
@@ -112,6 +185,135 @@ func (s *Select) With(o With) *Select {
 	return s
 }
 
+// Returns a deep copy of the query: modifying the copy (or any of its joins,
+// unions, CTEs and clauses) does not affect the original and vice versa.
+func (s *Select) Clone() *Select {
+	c := *s
+	c.Options = *s.Options.Clone()
+	c.Args = cloneArgs(s.Args)
+	c.FieldExprs = append([]Expression(nil), s.FieldExprs...)
+	c.Joins = cloneJoins(s.Joins)
+	if s.Unions != nil {
+		c.Unions = make([]*Select, len(s.Unions))
+		for i, u := range s.Unions {
+			c.Unions[i] = u.Clone()
+		}
+	}
+	if s.CTEs != nil {
+		c.CTEs = make([]With, len(s.CTEs))
+		for i, w := range s.CTEs {
+			c.CTEs[i] = With{
+				Name:      w.Name,
+				SubSelect: w.SubSelect.Clone(),
+			}
+		}
+	}
+	return &c
+}
+
+// Splits a list of fields on the commas that aren't nested in parentheses or
+// quotes
+func splitFields(fields string) []string {
+	result := make([]string, 0)
+	depth := 0
+	quote := rune(0)
+	start := 0
+	for i, r := range fields {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			result = append(result, strings.TrimSpace(fields[start:i]))
+			start = i + 1
+		}
+	}
+	if field := strings.TrimSpace(fields[start:]); field != "" {
+		result = append(result, field)
+	}
+	return result
+}
+
+// Number of fields the query selects, -1 if unknown (e.g. SELECT *)
+func (s *Select) fieldCount() int {
+	fields := splitFields(s.Fields)
+	for _, field := range fields {
+		if field == "*" || strings.HasSuffix(field, ".*") {
+			return -1
+		}
+	}
+	return len(fields) + len(s.FieldExprs)
+}
+
+// Replaces the n-th selected field, only use this on a clone
+func (s *Select) replaceField(n int, e Expression) {
+	if len(s.Unions) > 0 {
+		panic("Can't replace a field of a select with unions")
+	}
+	fields := splitFields(s.Fields)
+	if n >= len(fields) {
+		s.FieldExprs[n-len(fields)] = e
+		return
+	}
+
+	// Fields after the replaced one move to the expressions, which are bound
+	// after Args
+	rest := fields[n+1:]
+	if len(rest) > 0 && len(s.Args) > 0 {
+		panic("Can't replace a field of a select with arguments")
+	}
+	exprs := []Expression{e}
+	for _, field := range rest {
+		exprs = append(exprs, Col(field))
+	}
+	s.Fields = strings.Join(fields[:n], ", ")
+	s.FieldExprs = append(exprs, s.FieldExprs...)
+}
+
+// Applies the given scopes to the query
+func (s *Select) Scopes(scopes ...Scope) *Select {
+	for _, scope := range scopes {
+		if scope.Select != nil {
+			scope.Select(s)
+		}
+	}
+	return s
+}
+
+// Skips the default scopes registered on the builder for this table
+func (s *Select) Unscoped() *Select {
+	s.unscoped = true
+	return s
+}
+
+// Whether scopes, the tenant or soft delete filters (also of joined tables)
+// still need to be applied, see Builder
+func (s *Select) hasDefaults() bool {
+	return (len(s.defaultScopes) > 0 && !s.unscoped) || s.tenant != nil || s.softDelete != "" || (len(s.models) > 0 && len(s.Joins) > 0)
+}
+
+// Applies the builder defaults, only use this on a clone
+func (s *Select) applyDefaults() {
+	if !s.unscoped {
+		s.Scopes(s.defaultScopes...)
+	}
+	if s.tenant != nil {
+		s.applyTenant(s.tenant)
+	}
+	s.applySoftDelete()
+	s.defaultScopes = nil
+	s.tenant = nil
+	s.softDelete = ""
+	s.models = nil
+}
+
 func (s *Select) ToSQL() (string, []any) {
 	return s.toSQL(0)
 }
@@ -122,6 +324,12 @@ func (s *Select) ToSQLArgs(existingArgs []any) (string, []any) {
 }
 
 func (s *Select) toSQL(offset int) (string, []any) {
+	if s.hasDefaults() {
+		c := s.Clone()
+		c.applyDefaults()
+		return c.toSQL(offset)
+	}
+
 	b := strings.Builder{}
 	args := make([]any, 0)
 
@@ -138,29 +346,41 @@ func (s *Select) toSQL(offset int) (string, []any) {
 		b.WriteString("\n")
 	}
 
+	if hasSQLValue(s.Args) {
+		panic("Select args can't be SQL values such as Now(), use AddFields() instead")
+	}
 	args = append(args, s.Args...)
-	b.WriteString(fmt.Sprintf("SELECT %s FROM %s", s.Fields, s.Table))
-	for _, join := range s.Joins {
-		b.WriteString(" ")
-		b.WriteString(join.Join)
-		b.WriteString(" JOIN ")
-		b.WriteString(join.Table)
-		b.WriteString(" ON ")
-		q, v := join.On.Generate(offset+len(args), s.Dialect)
-		b.WriteString(q)
+	fields := make([]string, 0)
+	if s.Fields != "" {
+		fields = append(fields, s.Fields)
+	}
+	for _, e := range s.FieldExprs {
+		q, v := e.generateField(offset+len(args), s.Dialect)
+		fields = append(fields, q)
 		args = append(args, v...)
 	}
-	if !s.Options.Where.IsEmpty() {
-		q, v := s.Options.Where.Generate(offset+len(args), s.Dialect)
+	b.WriteString(fmt.Sprintf("SELECT %s FROM %s", strings.Join(fields, ", "), s.Table))
+	joins, joinArgs := generateJoins(s.Joins, offset+len(args), s.Dialect)
+	b.WriteString(joins)
+	args = append(args, joinArgs...)
+	where, whereArgs := bindArgs(s.Options.Where, s.Options.Args)
+	if !where.IsEmpty() {
+		q, v := where.Generate(offset+len(args), s.Dialect)
 		if len(q) > 0 {
 			b.WriteString(" WHERE ")
 			b.WriteString(q)
 			args = append(args, v...)
 		}
 	}
-	if s.Options.GroupBy != "" {
+	if hasSQLValue(whereArgs) {
+		panic("Options.Args can't be SQL values such as Now(), pass them to Expr() instead")
+	}
+	args = append(args, whereArgs...)
+	if len(s.Options.GroupBy) > 0 {
+		q, v := generateGroups(s.Options.GroupBy, offset+len(args), s.Dialect, true)
 		b.WriteString(" GROUP BY ")
-		b.WriteString(s.Options.GroupBy)
+		b.WriteString(strings.Join(q, ", "))
+		args = append(args, v...)
 	}
 	if !s.Options.Having.IsEmpty() {
 		q, v := s.Options.Having.Generate(offset+len(args), s.Dialect)
@@ -178,7 +398,9 @@ func (s *Select) toSQL(offset int) (string, []any) {
 	}
 	if len(s.Options.OrderBy) > 0 {
 		b.WriteString(" ORDER BY ")
-		b.WriteString(strings.Join(s.Options.OrderBy, ", "))
+		q, v := generateOrders(s.Options.OrderBy, offset+len(args), s.Dialect)
+		b.WriteString(q)
+		args = append(args, v...)
 	}
 	if s.Options.Limit > 0 {
 		b.WriteString(" LIMIT ")
@@ -186,15 +186,23 @@ func TestMerge(t *testing.T) {
 	}).Merge(&Options{
 		Offset:  20,
 		Limit:   15,
-		OrderBy: []string{"name DESC"},
+		OrderBy: []Order{Desc("name")},
 	})
 
 	assert.Equal(o, &Options{
 		Where:   FieldEquals("test", 123),
 		Offset:  20,
 		Limit:   15,
-		OrderBy: []string{"name DESC"},
+		OrderBy: []Order{Desc("name")},
 	})
+
+	o = (&Options{
+		GroupBy: []Group{GroupField("country")},
+	}).Merge(&Options{
+		GroupBy: []Group{GroupField("city")},
+	})
+
+	assert.Equal(o.GroupBy, []Group{GroupField("country"), GroupField("city")})
 }
 
 func TestGroupBy(t *testing.T) {
@@ -207,6 +215,68 @@ func TestGroupBy(t *testing.T) {
 	s, v := b.Select("sum(age)", "contacts").GroupBy("gender").ToSQL()
 	assert.Equal(s, "SELECT sum(age) FROM contacts GROUP BY gender")
 	assert.Equal(len(v), 0)
+
+	s, v = b.Select("sum(age)", "contacts").GroupBy("gender").GroupBy("country", "city").ToSQL()
+	assert.Equal(s, "SELECT sum(age) FROM contacts GROUP BY gender, country, city")
+	assert.Equal(len(v), 0)
+
+	s, v = b.Select("count(1)", "orders").
+		Where(FieldEquals("shop", 3)).
+		GroupByExpr("date_trunc(?, created_at)", "month").
+		Having(FieldGreaterThan("count(1)", 10)).
+		ToSQL()
+	assert.Equal(s, "SELECT count(1) FROM orders WHERE shop=$1 GROUP BY date_trunc($2, created_at) HAVING count(1)>$3")
+	assert.Equal(v, []any{3, "month", 10})
+}
+
+func TestGroupingSets(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, v := b.Select("country, city, sum(total)", "orders").
+		GroupBy("year").
+		Group(Rollup(GroupField("country"), GroupField("city"))).
+		ToSQL()
+	assert.Equal(s, "SELECT country, city, sum(total) FROM orders GROUP BY year, ROLLUP (country, city)")
+	assert.Equal(len(v), 0)
+
+	s, v = b.Select("country, sum(total)", "orders").
+		Group(Cube(GroupField("country"), GroupExpr("extract(? from created_at)", "year"))).
+		ToSQL()
+	assert.Equal(s, "SELECT country, sum(total) FROM orders GROUP BY CUBE (country, extract($1 from created_at))")
+	assert.Equal(v, []any{"year"})
+
+	s, v = b.Select("country, city, sum(total)", "orders").
+		Where(FieldEquals("shop", 1)).
+		Group(GroupingSets(
+			[]Group{GroupField("country"), GroupField("city")},
+			[]Group{GroupExpr("left(city, ?)", 2)},
+			[]Group{},
+		)).
+		ToSQL()
+	assert.Equal(s, "SELECT country, city, sum(total) FROM orders WHERE shop=$1 GROUP BY GROUPING SETS ((country, city), (left(city, $2)), ())")
+	assert.Equal(v, []any{1, 2})
+
+	m := NewBuilder(MySQLDialect{})
+
+	s, v = m.Select("country, city, sum(total)", "orders").
+		Group(Rollup(GroupField("country"), GroupField("city"))).
+		ToSQL()
+	assert.Equal(s, "SELECT country, city, sum(total) FROM orders GROUP BY country, city WITH ROLLUP")
+	assert.Equal(len(v), 0)
+
+	assert.Panics(func() {
+		m.Select("*", "orders").GroupBy("year").Group(Rollup(GroupField("country"))).ToSQL()
+	})
+	assert.Panics(func() {
+		m.Select("*", "orders").Group(Cube(GroupField("country"))).ToSQL()
+	})
+	assert.Panics(func() {
+		NewBuilder(SqliteDialect{}).Select("*", "orders").Group(Rollup(GroupField("country"))).ToSQL()
+	})
 }
 
 func TestUnion(t *testing.T) {
@@ -250,3 +320,172 @@ func TestCTE(t *testing.T) {
     other AS (SELECT * FROM test WHERE field=$2)
 SELECT hour, sum(count) over (order by hour asc rows between unbounded preceding and current row) FROM data WHERE x=$3`, s)
 }
+
+func TestSelectClone(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	base := b.Select("c.*", "contacts c").
+		With(With{
+			Name:      "recent",
+			SubSelect: b.Select("id", "orders").Where(FieldGreaterThan("created", 10)),
+		}).
+		Join("recent r", Expr("r.id=c.id")).
+		Where(FieldEquals("c.activated", true)).
+		GroupBy("c.id").
+		OrderBy("c.name").
+		Union(b.Select("c.*", "archived c").Where(FieldEquals("c.kept", true)))
+
+	baseSQL, baseArgs := base.ToSQL()
+
+	a := base.Clone()
+	a.Where(FieldEquals("c.country", "BE")).OrderByDesc("c.id").GroupBy("c.name").LeftJoin("notes n", Expr("n.contact=c.id"))
+	a.CTEs[0].SubSelect.Where(FieldEquals("shop", 2))
+	a.Unions[0].Where(FieldEquals("c.old", true))
+
+	c := base.Clone()
+	c.Where(FieldEquals("c.country", "NL"))
+
+	s, v := base.ToSQL()
+	assert.Equal(baseSQL, s)
+	assert.Equal(baseArgs, v)
+
+	s, v = a.ToSQL()
+	assert.Equal(`WITH
+    recent AS (SELECT id FROM orders WHERE created>$1 AND shop=$2)
+SELECT c.* FROM contacts c INNER JOIN recent r ON r.id=c.id LEFT JOIN notes n ON n.contact=c.id WHERE c.activated=$3 AND c.country=$4 GROUP BY c.id, c.name UNION SELECT c.* FROM archived c WHERE c.kept=$5 AND c.old=$6 ORDER BY c.name, c.id DESC`, s)
+	assert.Equal([]any{10, 2, true, "BE", true, true}, v)
+
+	s, v = c.ToSQL()
+	assert.Equal(`WITH
+    recent AS (SELECT id FROM orders WHERE created>$1)
+SELECT c.* FROM contacts c INNER JOIN recent r ON r.id=c.id WHERE c.activated=$2 AND c.country=$3 GROUP BY c.id UNION SELECT c.* FROM archived c WHERE c.kept=$4 ORDER BY c.name`, s)
+	assert.Equal([]any{10, true, "NL", true}, v)
+}
+
+func TestCombine(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	defaults := &Options{
+		Where:   FieldEquals("deleted", false),
+		Limit:   50,
+		OrderBy: []Order{Desc("created")},
+	}
+	filters := &Options{
+		Where:   Or(FieldEquals("country", "BE"), FieldEquals("country", "NL")),
+		Offset:  100,
+		GroupBy: []Group{GroupField("country")},
+		Having:  FieldGreaterThan("count(*)", 1),
+		Args:    []any{"x"},
+	}
+	permissions := &Options{
+		Where: FieldEquals("owner", 12),
+		Limit: NoLimit,
+		Args:  []any{"y"},
+	}
+
+	o := defaults.Combine(filters, nil, permissions)
+	assert.Equal(o.Limit, int64(0))
+	assert.Equal(o.Offset, int64(100))
+	assert.Equal(o.OrderBy, []Order{Desc("created")})
+	assert.Equal(o.GroupBy, []Group{GroupField("country")})
+	assert.Equal(o.Args, []any{"x", "y"})
+
+	s, v := o.Where.Generate(0, PostgreSQLDialect{})
+	assert.Equal(s, "deleted=$1 AND (country=$2 OR country=$3) AND owner=$4")
+	assert.Equal(v, []any{false, "BE", "NL", 12})
+
+	s, v = o.Having.Generate(0, PostgreSQLDialect{})
+	assert.Equal(s, "count(*)>$1")
+	assert.Equal(v, []any{1})
+
+	// Inputs are left untouched
+	assert.Equal(defaults.Where, FieldEquals("deleted", false))
+	assert.Equal(defaults.Limit, int64(50))
+	assert.Len(filters.Args, 1)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, v = b.Select("*", "contacts").
+		Where(FieldEquals("activated", true)).
+		Apply(defaults, &Options{Where: FieldEquals("owner", 12), Limit: 10}).
+		Where(FieldEquals("country", "BE")).
+		ToSQL()
+	assert.Equal(s, "SELECT * FROM contacts WHERE activated=$1 AND deleted=$2 AND owner=$3 AND country=$4 ORDER BY created DESC LIMIT 10")
+	assert.Equal(v, []any{true, false, 12, "BE"})
+}
+
+func TestApplyArgs(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	s, v := b.Select("id", "users", 1).
+		Where(FieldEquals("org", 2)).
+		Apply(&Options{
+			Where: Expr("age>? AND age<?"),
+			Args:  []any{18, 65},
+		}).
+		Order(Func("coalesce", Col("rank"), 0).Asc()).
+		ToSQL()
+	assert.Equal(s, "SELECT id FROM users WHERE org=$2 AND age>$3 AND age<$4 ORDER BY coalesce(rank, $5)")
+	assert.Equal(v, []any{1, 2, 18, 65, 0})
+}
+
+func TestApplyArgsOrder(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	for _, d := range []Dialect{MySQLDialect{}, PostgreSQLDialect{}} {
+		b := NewBuilder(d)
+
+		// Each option's Args stay with its own Where
+		s, v := b.Select("id", "docs").
+			Apply(
+				&Options{Where: Expr("owner=?"), Args: []any{"alice"}},
+				&Options{Where: FieldEquals("tenant", 7)},
+				&Options{Where: Expr("kind=? OR kind=?"), Args: []any{"a", "b"}},
+			).
+			ToSQL()
+		if _, ok := d.(MySQLDialect); ok {
+			assert.Equal(s, "SELECT id FROM docs WHERE owner=? AND tenant=? AND kind=? OR kind=?")
+		} else {
+			assert.Equal(s, "SELECT id FROM docs WHERE owner=$1 AND tenant=$2 AND kind=$3 OR kind=$4")
+		}
+		assert.Equal(v, []any{"alice", 7, "a", "b"})
+
+		// A raw Where followed by parameterized clauses in a single option
+		s, v = b.Select("id", "docs").
+			Apply(&Options{
+				Where: And(Expr("owner=?"), FieldEquals("tenant", 7), IsNull("deleted")),
+				Args:  []any{"alice"},
+			}).
+			ToSQL()
+		if _, ok := d.(MySQLDialect); ok {
+			assert.Equal(s, "SELECT id FROM docs WHERE (owner=? AND tenant=? AND deleted IS NULL)")
+		} else {
+			assert.Equal(s, "SELECT id FROM docs WHERE (owner=$1 AND tenant=$2 AND deleted IS NULL)")
+		}
+		assert.Equal(v, []any{"alice", 7})
+
+		s, v = b.Select("id", "docs").
+			Where(FieldEquals("tenant", 7)).
+			Apply(&Options{Where: Expr("owner=?"), Args: []any{"alice"}}).
+			Where(FieldEquals("kind", "a")).
+			ToSQL()
+		if _, ok := d.(MySQLDialect); ok {
+			assert.Equal(s, "SELECT id FROM docs WHERE tenant=? AND owner=? AND kind=?")
+		} else {
+			assert.Equal(s, "SELECT id FROM docs WHERE tenant=$1 AND owner=$2 AND kind=$3")
+		}
+		assert.Equal(v, []any{7, "alice", "a"})
+	}
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type deletedFilter int
+
+const (
+	excludeDeleted deletedFilter = iota
+	includeDeleted
+	onlyDeleted
+)
+
+// Includes soft-deleted rows in the results, also those of joined tables
+func (s *Select) WithDeleted() *Select {
+	s.deleted = includeDeleted
+	return s
+}
+
+// Only returns soft-deleted rows, soft-deleted rows of joined tables are still
+// skipped
+func (s *Select) OnlyDeleted() *Select {
+	s.deleted = onlyDeleted
+	return s
+}
+
+// Filters the table and the joined tables that use soft deletes, joined tables
+// are filtered in their ON clause so left joins still return the row.
+func (s *Select) applySoftDelete() {
+	if s.softDelete != "" {
+		column := fmt.Sprintf("%s.%s", tableAlias(s.Table), s.softDelete)
+		switch s.deleted {
+		case excludeDeleted:
+			s.Options.Where = andWhere(s.Options.Where, IsNull(column))
+		case onlyDeleted:
+			s.Options.Where = andWhere(s.Options.Where, IsNotNull(column))
+		}
+	}
+
+	if s.deleted == includeDeleted {
+		return
+	}
+	for j, join := range s.Joins {
+		m, ok := s.models[tableName(join.Table)]
+		if !ok || m.softDelete == "" {
+			continue
+		}
+		column := fmt.Sprintf("%s.%s", tableAlias(join.Table), m.softDelete)
+		s.Joins[j].On = And(join.On, IsNull(column))
+	}
+}
+
+// Removes the rows, even if the table uses soft deletes
+func (d *Delete) HardDelete() *Delete {
+	d.hardDelete = true
+	return d
+}
+
+// Sets the soft delete column to the time of the builder clock
+func (d *Delete) softDeleteSQL() (string, []any) {
+	now, vars := generateValue(d.clock.now(), 0, d.Dialect)
+	query := fmt.Sprintf("UPDATE %s SET %s=%s", d.Table, d.softDelete, now)
+	where, v := andWhere(d.where, IsNull(d.softDelete)).Generate(len(vars), d.Dialect)
+	vars = append(vars, v...)
+	query = fmt.Sprintf("%s WHERE %s", query, where)
+	if len(d.returning) > 0 {
+		query = fmt.Sprintf("%s RETURNING %s", query, strings.Join(d.returning, ", "))
+	}
+	return query, vars
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoftDelete(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Post struct {
+		ID        int64      `db:"id,autoincrement"`
+		Title     string     `db:"title"`
+		DeletedAt *time.Time `db:"deleted_at,softdelete"`
+	}
+
+	b := NewBuilder(PostgreSQLDialect{}).Register("posts", &Post{}).SetClock(DatabaseClock)
+
+	s, v := b.Delete("posts", IDEquals(3)).ToSQL()
+	assert.Equal(s, "UPDATE posts SET deleted_at=CURRENT_TIMESTAMP WHERE id=$1 AND deleted_at IS NULL")
+	assert.Equal(v, []any{3})
+
+	s, v = b.Delete("posts", IDEquals(3)).HardDelete().ToSQL()
+	assert.Equal(s, "DELETE FROM posts WHERE id=$1")
+	assert.Equal(v, []any{3})
+
+	s, v = b.Delete("posts", All()).ToSQL()
+	assert.Equal(s, "UPDATE posts SET deleted_at=CURRENT_TIMESTAMP WHERE deleted_at IS NULL")
+	assert.Len(v, 0)
+
+	s, v = b.Delete("posts", IDEquals(3)).Returning("id").ToSQL()
+	assert.Equal(s, "UPDATE posts SET deleted_at=CURRENT_TIMESTAMP WHERE id=$1 AND deleted_at IS NULL RETURNING id")
+	assert.Equal(v, []any{3})
+
+	assert.Panics(func() {
+		b.Delete("posts", All()).Using("users").ToSQL()
+	})
+
+	s, v = b.Select("*", "posts").Where(FieldEquals("title", "Hello")).ToSQL()
+	assert.Equal(s, "SELECT * FROM posts WHERE title=$1 AND posts.deleted_at IS NULL")
+	assert.Equal(v, []any{"Hello"})
+
+	s, v = b.Select("p.*", "posts p").WithDeleted().ToSQL()
+	assert.Equal(s, "SELECT p.* FROM posts p")
+	assert.Len(v, 0)
+
+	s, v = b.Select("p.*", "posts p").OnlyDeleted().ToSQL()
+	assert.Equal(s, "SELECT p.* FROM posts p WHERE p.deleted_at IS NOT NULL")
+	assert.Len(v, 0)
+
+	// Joined tables are filtered too
+	s, v = b.Select("c.*", "comments c").LeftJoin("posts p", Expr("p.id=c.post")).ToSQL()
+	assert.Equal(s, "SELECT c.* FROM comments c LEFT JOIN posts p ON (p.id=c.post AND p.deleted_at IS NULL)")
+	assert.Len(v, 0)
+
+	s, v = b.Select("p.*", "posts p").Join("posts o", Expr("o.id=p.original")).OnlyDeleted().ToSQL()
+	assert.Equal(s, "SELECT p.* FROM posts p INNER JOIN posts o ON (o.id=p.original AND o.deleted_at IS NULL) WHERE p.deleted_at IS NOT NULL")
+	assert.Len(v, 0)
+
+	s, v = b.Select("c.*", "comments c").Join("posts p", Expr("p.id=c.post")).WithDeleted().ToSQL()
+	assert.Equal(s, "SELECT c.* FROM comments c INNER JOIN posts p ON p.id=c.post")
+	assert.Len(v, 0)
+
+	// Uses the clock of the builder
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewBuilder(PostgreSQLDialect{}).Register("posts", &Post{}).SetClock(func() any {
+		return now
+	})
+	s, v = clock.Delete("posts", IDEquals(3)).ToSQL()
+	assert.Equal(s, "UPDATE posts SET deleted_at=$1 WHERE id=$2 AND deleted_at IS NULL")
+	assert.Equal(v, []any{now, 3})
+
+	// Other tables are unaffected
+	s, v = b.Delete("comments", IDEquals(3)).ToSQL()
+	assert.Equal(s, "DELETE FROM comments WHERE id=$1")
+	assert.Equal(v, []any{3})
+}
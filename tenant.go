@@ -0,0 +1,206 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Restricts all statements of a builder to a single tenant, see
+// Builder.WithTenant.
+type Tenant struct {
+	// Default tenant column
+	Column string
+	Value  any
+
+	// Overrides the column for specific tables, an empty column marks a table
+	// as shared between tenants (it will not be filtered).
+	Tables map[string]string
+}
+
+// Returns a copy of the builder that restricts all statements to the given
+// tenant: selects, updates and deletes (including joined tables) get a tenant
+// filter, inserts set the tenant column.
+//
+// Subqueries and CTEs are filtered as long as they're created with the
+// returned builder.
+func (b *Builder) WithTenant(tenant Tenant) *Builder {
+	c := *b
+	c.tenant = &tenant
+	return &c
+}
+
+func (t *Tenant) column(table string) string {
+	name := tableName(table)
+	if strings.HasPrefix(name, "(") {
+		return ""
+	}
+	if column, ok := t.Tables[name]; ok {
+		return column
+	}
+	return t.Column
+}
+
+// Returns the tenant filter for the given table expression, if any
+func (t *Tenant) where(table string) (Where, bool) {
+	column := t.column(table)
+	if column == "" {
+		return All(), false
+	}
+	return FieldEquals(fmt.Sprintf("%s.%s", tableAlias(table), column), t.Value), true
+}
+
+// Returns the name by which a table expression (e.g. "contacts c") is referred to
+func tableAlias(table string) string {
+	fields := strings.Fields(table)
+	switch {
+	case len(fields) == 2:
+		return fields[1]
+	case len(fields) == 3 && strings.EqualFold(fields[1], "AS"):
+		return fields[2]
+	default:
+		return tableName(table)
+	}
+}
+
+// Filters the table and all joined tables, CTEs are filtered by their own
+// query so references to them are skipped.
+func (s *Select) applyTenant(t *Tenant) {
+	isCTE := func(table string) bool {
+		for _, cte := range s.CTEs {
+			if cte.Name == tableName(table) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if w, ok := t.where(s.Table); ok && !isCTE(s.Table) {
+		s.Options.Where = andWhere(s.Options.Where, w)
+	}
+	for j, join := range s.Joins {
+		if w, ok := t.where(join.Table); ok && !isCTE(join.Table) {
+			s.Joins[j].On = And(join.On, w)
+		}
+	}
+}
+
+func (i *InsertUpdate) applyTenant(t *Tenant) {
+	switch i.mode {
+	case updateMode:
+		if w, ok := t.where(i.Table); ok {
+			i.Where(w)
+		}
+		for j, join := range i.joins {
+			if w, ok := t.where(join.Table); ok {
+				i.joins[j].On = And(join.On, w)
+			}
+		}
+	default:
+		column := t.column(i.Table)
+		if column == "" {
+			return
+		}
+		if i.mode == upsertMode {
+			i.conflict = t.scopeConflict(i.Table, i.conflict, i.conflictColumn, i.dialect, i.crossTenant)
+		}
+		if i.fromSelect != nil {
+			i.applySelectTenant(column, t.Value)
+		}
+		if i.selectsColumn(column) {
+			return
+		}
+		for j, field := range i.fields {
+			if field.key == column {
+				i.fields[j] = fieldValue{key: column, value: t.Value}
+				return
+			}
+		}
+		i.Add(column, t.Value)
+	}
+}
+
+// Forces the tenant of the rows inserted by an INSERT ... SELECT: a selected
+// tenant column is replaced by the tenant value.
+func (i *InsertUpdate) applySelectTenant(column string, value any) {
+	if len(i.fromColumns) == 0 {
+		panic("Select() needs a column list to set the tenant column")
+	}
+	for n, c := range i.fromColumns {
+		if c != column {
+			continue
+		}
+		if i.fromSelect.fieldCount() != len(i.fromColumns) {
+			panic("The select fields don't match the columns of INSERT ... SELECT")
+		}
+		i.fromSelect.replaceField(n, Param(value))
+	}
+}
+
+// Keeps an upsert from updating the rows of other tenants on a conflict. The
+// conflict is nil for the default upsert of the dialect.
+//
+// MySQL can't restrict the update, so its upserts panic unless they allow
+// cross-tenant updates or do nothing on a conflict.
+func (t *Tenant) scopeConflict(table string, conflict *Conflict, conflictColumn []string, dialect Dialect, crossTenant bool) *Conflict {
+	w, ok := t.where(table)
+	if !ok {
+		return conflict
+	}
+
+	if !dialect.SupportsConflictWhere() {
+		if (conflict == nil || !conflict.doNothing) && !crossTenant {
+			panic("Upserts can't be restricted to a tenant on this dialect, use AllowCrossTenantUpsert() to allow overwriting rows of other tenants")
+		}
+		return conflict
+	}
+
+	if conflict == nil {
+		if len(conflictColumn) == 0 {
+			// DO NOTHING
+			return nil
+		}
+		conflict = OnConflict(conflictColumn...)
+	}
+	if conflict.doNothing {
+		return conflict
+	}
+	return conflict.Where(w)
+}
+
+func (d *Delete) applyTenant(t *Tenant) {
+	if w, ok := t.where(d.Table); ok {
+		d.Where(w)
+	}
+	for _, table := range d.using {
+		if w, ok := t.where(table); ok {
+			d.Where(w)
+		}
+	}
+	for j, join := range d.joins {
+		if w, ok := t.where(join.Table); ok {
+			d.joins[j].On = And(join.On, w)
+		}
+	}
+}
+
+func (i *BulkInsert) applyTenant(t *Tenant) {
+	column := t.column(i.Table)
+	if column == "" {
+		return
+	}
+	if i.mode == upsertMode {
+		i.conflict = t.scopeConflict(i.Table, i.conflict, i.conflictColumn, i.Dialect, i.crossTenant)
+	}
+	for n, c := range i.Columns {
+		if c == column {
+			for _, row := range i.Values {
+				row[n] = t.Value
+			}
+			return
+		}
+	}
+	i.Columns = append(i.Columns, column)
+	for j, row := range i.Values {
+		i.Values[j] = append(row, t.Value)
+	}
+}
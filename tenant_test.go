@@ -0,0 +1,141 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{}).WithTenant(Tenant{
+		Column: "tenant_id",
+		Value:  7,
+		Tables: map[string]string{
+			"countries": "",
+			"accounts":  "id",
+		},
+	})
+
+	s, v := b.Select("*", "contacts").Where(IDEquals(3)).ToSQL()
+	assert.Equal(s, "SELECT * FROM contacts WHERE id=$1 AND contacts.tenant_id=$2")
+	assert.Equal(v, []any{3, 7})
+
+	s, v = b.Select("c.*", "contacts c").
+		Join("addresses a", Expr("a.contact=c.id")).
+		LeftJoin("countries co", Expr("co.id=a.country")).
+		Join("accounts acc", Expr("acc.id=c.tenant_id")).
+		ToSQL()
+	assert.Equal(s, "SELECT c.* FROM contacts c INNER JOIN addresses a ON (a.contact=c.id AND a.tenant_id=$1) LEFT JOIN countries co ON co.id=a.country INNER JOIN accounts acc ON (acc.id=c.tenant_id AND acc.id=$2) WHERE c.tenant_id=$3")
+	assert.Equal(v, []any{7, 7, 7})
+
+	s, v = b.Select("c.*", "recent r").
+		With(With{
+			Name:      "recent",
+			SubSelect: b.Select("contact", "orders AS o").Where(FieldGreaterThan("o.created", 10)),
+		}).
+		Join("contacts c", Expr("c.id=r.contact")).
+		Where(In("c.id", b.Select("contact", "invoices"))).
+		Where(Exists(b.Select("1", "notes n").Where(Expr("n.contact=c.id")))).
+		ToSQL()
+	assert.Equal(`WITH
+    recent AS (SELECT contact FROM orders AS o WHERE o.created>$1 AND o.tenant_id=$2)
+SELECT c.* FROM recent r INNER JOIN contacts c ON (c.id=r.contact AND c.tenant_id=$3) WHERE c.id IN (SELECT contact FROM invoices WHERE invoices.tenant_id=$4) AND EXISTS (SELECT 1 FROM notes n WHERE n.contact=c.id AND n.tenant_id=$5)`, s)
+	assert.Equal(v, []any{10, 7, 7, 7, 7})
+
+	s, v = b.Select("*", "countries").ToSQL()
+	assert.Equal(s, "SELECT * FROM countries")
+	assert.Len(v, 0)
+
+	s, v = b.Update("contacts", IDEquals(3)).Add("name", "Jack").ToSQL()
+	assert.Equal(s, "UPDATE contacts SET name=$1 WHERE id=$2 AND contacts.tenant_id=$3")
+	assert.Equal(v, []any{"Jack", 3, 7})
+
+	s, v = b.Delete("contacts", IDEquals(3)).ToSQL()
+	assert.Equal(s, "DELETE FROM contacts WHERE id=$1 AND contacts.tenant_id=$2")
+	assert.Equal(v, []any{3, 7})
+
+	s, v = b.Insert("contacts").Add("name", "Jack").ToSQL()
+	assert.Equal(s, "INSERT INTO contacts (name, tenant_id) VALUES ($1, $2)")
+	assert.Equal(v, []any{"Jack", 7})
+
+	s, v = b.Upsert("contacts", "id").Add("tenant_id", 8).Add("name", "Jack").ToSQL()
+	assert.Equal(s, "INSERT INTO contacts (tenant_id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET tenant_id=EXCLUDED.tenant_id, name=EXCLUDED.name WHERE contacts.tenant_id=$3")
+	assert.Equal(v, []any{7, "Jack", 7})
+
+	insert := b.BulkInsert("contacts", []string{"name"})
+	assert.NoError(insert.Add("Jack"))
+	assert.NoError(insert.Add("Bob"))
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO contacts (name, tenant_id) VALUES ($1, $2), ($3, $4)")
+	assert.Equal(v, []any{"Jack", 7, "Bob", 7})
+
+	// Unscoped does not disable the tenant filter
+	s, v = b.Select("*", "contacts").Unscoped().ToSQL()
+	assert.Equal(s, "SELECT * FROM contacts WHERE contacts.tenant_id=$1")
+	assert.Equal(v, []any{7})
+}
+
+func TestTenantUpsert(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	tenant := Tenant{
+		Column: "tenant_id",
+		Value:  7,
+	}
+
+	// A conflict with a row of another tenant leaves that row untouched
+	b := NewBuilder(PostgreSQLDialect{}).WithTenant(tenant)
+	s, v := b.Upsert("contacts", "id").Add("id", 3).Add("name", "Jack").ToSQL()
+	assert.Equal(s, "INSERT INTO contacts (id, name, tenant_id) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET id=EXCLUDED.id, name=EXCLUDED.name, tenant_id=EXCLUDED.tenant_id WHERE contacts.tenant_id=$4")
+	assert.Equal(v, []any{3, "Jack", 7, 7})
+
+	s, v = b.Insert("contacts").Add("id", 3).Add("name", "Jack").
+		OnConflict(OnConflict("id").Exclude("id").Where(FieldNotEquals("name", "Bob"))).ToSQL()
+	assert.Equal(s, "INSERT INTO contacts (id, name, tenant_id) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET name=EXCLUDED.name, tenant_id=EXCLUDED.tenant_id WHERE name!=$4 AND contacts.tenant_id=$5")
+	assert.Equal(v, []any{3, "Jack", 7, "Bob", 7})
+
+	s, v = b.Insert("contacts").Add("id", 3).OnConflict(OnConflict("id").DoNothing()).ToSQL()
+	assert.Equal(s, "INSERT INTO contacts (id, tenant_id) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING")
+	assert.Equal(v, []any{3, 7})
+
+	b = NewBuilder(SqliteDialect{}).WithTenant(tenant)
+	insert := b.BulkUpsert("contacts", []string{"id", "name"}, []string{"id"})
+	assert.NoError(insert.Add(3, "Jack"))
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO contacts (id, name, tenant_id) VALUES (?, ?, ?) ON CONFLICT (id) DO UPDATE SET id=EXCLUDED.id, name=EXCLUDED.name, tenant_id=EXCLUDED.tenant_id WHERE contacts.tenant_id=?")
+	assert.Equal(v, []any{3, "Jack", 7, 7})
+
+	// MySQL can't restrict the update to the tenant
+	b = NewBuilder(MySQLDialect{}).WithTenant(tenant)
+	assert.Panics(func() {
+		b.Upsert("contacts", "id").Add("id", 3).ToSQL()
+	})
+	assert.Panics(func() {
+		b.Insert("contacts").Add("id", 3).OnConflict(OnConflict("id")).ToSQL()
+	})
+	assert.Panics(func() {
+		insert := b.BulkUpsert("contacts", []string{"id"}, []string{"id"})
+		_ = insert.Add(3)
+		insert.ToSQL()
+	})
+
+	s, _ = b.Insert("contacts").Add("id", 3).OnConflict(OnConflict("id").DoNothing()).ToSQL()
+	assert.Equal(s, "INSERT IGNORE INTO contacts (id, tenant_id) VALUES (?, ?)")
+
+	assert.Panics(func() {
+		b.Upsert("contacts", "id").Add("id", 3).Unscoped().ToSQL()
+	})
+	s, _ = b.Upsert("contacts", "id").Add("id", 3).AllowCrossTenantUpsert().ToSQL()
+	assert.Equal(s, "REPLACE INTO contacts (id, tenant_id) VALUES (?, ?)")
+
+	insert = b.BulkUpsert("contacts", []string{"id"}, []string{"id"}).AllowCrossTenantUpsert()
+	assert.NoError(insert.Add(3))
+	s, _ = insert.ToSQL()
+	assert.Equal(s, "REPLACE INTO contacts (id, tenant_id) VALUES (?, ?)")
+}
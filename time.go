@@ -1,13 +1,96 @@
 package query
 
-import "database/sql/driver"
+import "time"
 
 type now struct{}
 
-func Now() driver.Valuer {
-	return &now{}
+// The current time of the database server, rendered as SQL (e.g. NOW()) by
+// the dialect rather than bound as a parameter.
+func Now() SQLValue {
+	return now{}
 }
 
-func (n *now) Value() (driver.Value, error) {
-	return "CURRENT_TIMESTAMP", nil
+func (n now) SQL(dialect Dialect) string {
+	return dialect.Now()
+}
+
+// Returns the value stored in autocreate / autoupdate columns
+type Clock func() any
+
+// Uses the current time of the database server
+func DatabaseClock() any {
+	return Now()
+}
+
+func (c Clock) now() any {
+	if c == nil {
+		return time.Now()
+	}
+	return c()
+}
+
+func (i *InsertUpdate) hasTimestamps() bool {
+	return i.model != nil && len(i.model.autoCreate)+len(i.model.autoUpdate) > 0
+}
+
+// Sets the timestamp columns of the table model that weren't set explicitly
+func (i *InsertUpdate) applyTimestamps() {
+	if i.fromSelect != nil && len(i.fromColumns) == 0 {
+		return
+	}
+
+	now := i.clock.now()
+	set := func(column string, insertOnly bool) {
+		if i.selectsColumn(column) {
+			return
+		}
+		for _, field := range i.fields {
+			if field.key == column {
+				return
+			}
+		}
+		i.fields = append(i.fields, fieldValue{key: column, value: now, insertOnly: insertOnly})
+	}
+
+	if i.mode != updateMode {
+		for _, column := range i.model.autoCreate {
+			set(column, true)
+		}
+	}
+	for _, column := range i.model.autoUpdate {
+		set(column, false)
+	}
+}
+
+func (i *BulkInsert) hasTimestamps() bool {
+	return i.model != nil && len(i.model.autoCreate)+len(i.model.autoUpdate) > 0
+}
+
+// Adds the timestamp columns of the table model that weren't set explicitly
+func (i *BulkInsert) applyTimestamps() {
+	now := i.clock.now()
+	columns := append(append([]string(nil), i.model.autoCreate...), i.model.autoUpdate...)
+	for _, column := range columns {
+		if i.model.isAutoCreate(column) {
+			if i.insertOnly == nil {
+				i.insertOnly = make(map[string]bool)
+			}
+			i.insertOnly[column] = true
+		}
+
+		exists := false
+		for _, c := range i.Columns {
+			if c == column {
+				exists = true
+			}
+		}
+		if exists {
+			continue
+		}
+
+		i.Columns = append(i.Columns, column)
+		for j, row := range i.Values {
+			i.Values[j] = append(row, now)
+		}
+	}
 }
@@ -0,0 +1,169 @@
+package query
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestamps(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Post struct {
+		ID        int64     `db:"id,autoincrement"`
+		Title     string    `db:"title"`
+		CreatedAt time.Time `db:"created_at,autocreate"`
+		UpdatedAt time.Time `db:"updated_at,autoupdate"`
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuilder(PostgreSQLDialect{}).SetClock(func() any {
+		return now
+	})
+
+	post := &Post{ID: 1, Title: "Hello"}
+
+	s, v := b.Insert("posts").With(post).ToSQL()
+	assert.Equal(s, "INSERT INTO posts (id, title, created_at, updated_at) VALUES ($1, $2, $3, $4)")
+	assert.Equal(v, []any{int64(1), "Hello", now, now})
+
+	s, v = b.Update("posts", IDEquals(1)).With(post).ToSQL()
+	assert.Equal(s, "UPDATE posts SET title=$1, updated_at=$2 WHERE id=$3")
+	assert.Equal(v, []any{"Hello", now, 1})
+
+	s, v = b.Upsert("posts", "id").With(post).ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, created_at, updated_at) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET title=EXCLUDED.title, updated_at=EXCLUDED.updated_at")
+	assert.Equal(v, []any{"Hello", now, now})
+
+	// MySQL can't keep created_at with REPLACE
+	my := NewBuilder(MySQLDialect{}).SetClock(func() any {
+		return now
+	})
+	s, v = my.Upsert("posts", "id").With(post).ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, created_at, updated_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE title=VALUES(title), updated_at=VALUES(updated_at)")
+	assert.Equal(v, []any{"Hello", now, now})
+
+	type Draft struct {
+		Title     string    `db:"title"`
+		CreatedAt time.Time `db:"created_at,autocreate"`
+	}
+	registered := NewBuilder(MySQLDialect{}).Register("posts", Draft{}).SetClock(func() any {
+		return now
+	})
+	insert := registered.BulkUpsert("posts", []string{"title"}, []string{"id"})
+	assert.NoError(insert.Add("Hello"))
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, created_at) VALUES (?, ?) ON DUPLICATE KEY UPDATE title=VALUES(title)")
+	assert.Equal(v, []any{"Hello", now})
+
+	s, _ = my.Upsert("posts", "id").Add("title", "Hello").ToSQL()
+	assert.Equal(s, "REPLACE INTO posts (title) VALUES (?)")
+}
+
+func TestTimestampsRegistered(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	type Post struct {
+		Title     string    `db:"title"`
+		CreatedAt time.Time `db:"created_at,autocreate"`
+		UpdatedAt time.Time `db:"updated_at,autoupdate"`
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := NewBuilder(PostgreSQLDialect{}).Register("posts", Post{}).SetClock(func() any {
+		return now
+	})
+
+	s, v := b.Insert("posts").Add("title", "Hello").ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, created_at, updated_at) VALUES ($1, $2, $3)")
+	assert.Equal(v, []any{"Hello", now, now})
+
+	s, v = b.Update("posts", IDEquals(1)).Add("title", "Hello").ToSQL()
+	assert.Equal(s, "UPDATE posts SET title=$1, updated_at=$2 WHERE id=$3")
+	assert.Equal(v, []any{"Hello", now, 1})
+
+	insert := b.BulkInsert("posts", []string{"title", "created_at"})
+	assert.NoError(insert.Add("Hello", now.Add(-time.Hour)))
+	assert.NoError(insert.Add("World", now.Add(-time.Minute)))
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, created_at, updated_at) VALUES ($1, $2, $3), ($4, $5, $6)")
+	assert.Equal(v, []any{"Hello", now.Add(-time.Hour), now, "World", now.Add(-time.Minute), now})
+
+	upsert := b.BulkUpsert("posts", []string{"title"}, []string{"title"})
+	assert.NoError(upsert.Add("Hello"))
+	s, v = upsert.ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, created_at, updated_at) VALUES ($1, $2, $3) ON CONFLICT (title) DO UPDATE SET title=EXCLUDED.title, updated_at=EXCLUDED.updated_at")
+	assert.Equal(v, []any{"Hello", now, now})
+
+	db := NewBuilder(PostgreSQLDialect{}).Register("posts", Post{}).SetClock(DatabaseClock)
+	s, v = db.Update("posts", IDEquals(1)).Add("title", "Hello").ToSQL()
+	assert.Equal(s, "UPDATE posts SET title=$1, updated_at=CURRENT_TIMESTAMP WHERE id=$2")
+	assert.Equal(v, []any{"Hello", 1})
+}
+
+func TestNow(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	b := NewBuilder(PostgreSQLDialect{})
+
+	// Never bound as a parameter
+	_, ok := Now().(driver.Valuer)
+	assert.False(ok)
+
+	// Also inlined as an argument of raw SQL
+	s, v := b.Select("id", "posts").Where(Expr("created<? AND author=?", Now(), 3)).ToSQL()
+	assert.Equal(s, "SELECT id FROM posts WHERE created<CURRENT_TIMESTAMP AND author=$1")
+	assert.Equal(v, []any{3})
+
+	s, v = b.Update("posts", IDEquals(1)).SetExpr("expires", "? + ?::interval", Now(), "1 day").ToSQL()
+	assert.Equal(s, "UPDATE posts SET expires=CURRENT_TIMESTAMP + $1::interval WHERE id=$2")
+	assert.Equal(v, []any{"1 day", 1})
+
+	s, v = b.Select("id", "posts").Apply(&Options{Where: Expr("created<?"), Args: []any{Now()}}).ToSQL()
+	assert.Equal(s, "SELECT id FROM posts WHERE created<CURRENT_TIMESTAMP")
+	assert.Len(v, 0)
+
+	assert.Panics(func() {
+		b.Select("?", "posts", Now()).ToSQL()
+	})
+
+	s, v = b.Insert("posts").Add("title", "Hello").Add("created_at", Now()).Add("author", 3).ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, created_at, author) VALUES ($1, CURRENT_TIMESTAMP, $2)")
+	assert.Equal(v, []any{"Hello", 3})
+
+	s, v = b.Update("posts", And(IDEquals(1), FieldLessThan("published_at", Now()), FieldEquals("author", 3))).
+		Add("updated_at", Now()).
+		Add("title", "Hello").
+		ToSQL()
+	assert.Equal(s, "UPDATE posts SET updated_at=CURRENT_TIMESTAMP, title=$1 WHERE (id=$2 AND published_at<CURRENT_TIMESTAMP AND author=$3)")
+	assert.Equal(v, []any{"Hello", 1, 3})
+
+	s, v = b.Upsert("posts", "id").Add("id", 1).Add("updated_at", Now()).Add("title", "Hello").ToSQL()
+	assert.Equal(s, "INSERT INTO posts (id, updated_at, title) VALUES ($1, CURRENT_TIMESTAMP, $2) ON CONFLICT (id) DO UPDATE SET id=EXCLUDED.id, updated_at=EXCLUDED.updated_at, title=EXCLUDED.title")
+	assert.Equal(v, []any{1, "Hello"})
+
+	insert := b.BulkInsert("posts", []string{"title", "created_at"})
+	assert.NoError(insert.Add("Hello", Now()))
+	assert.NoError(insert.Add("World", Raw("DEFAULT")))
+	s, v = insert.ToSQL()
+	assert.Equal(s, "INSERT INTO posts (title, created_at) VALUES ($1, CURRENT_TIMESTAMP), ($2, DEFAULT)")
+	assert.Equal(v, []any{"Hello", "World"})
+
+	s, v = b.Select("*", "posts").Where(FieldIn("status", []any{1, Raw("'draft'"), 3})).ToSQL()
+	assert.Equal(s, "SELECT * FROM posts WHERE status IN ($1, 'draft', $2)")
+	assert.Equal(v, []any{1, 3})
+
+	s, _ = NewBuilder(MySQLDialect{}).Insert("posts").Add("created_at", Now()).ToSQL()
+	assert.Equal(s, "INSERT INTO posts (created_at) VALUES (NOW())")
+
+	s, _ = NewBuilder(SqliteDialect{}).Insert("posts").Add("created_at", Now()).ToSQL()
+	assert.Equal(s, "INSERT INTO posts (created_at) VALUES (datetime('now'))")
+}
@@ -0,0 +1,67 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A value that is written into the query as SQL instead of being bound as a
+// parameter, such as Now().
+type SQLValue interface {
+	SQL(dialect Dialect) string
+}
+
+type rawValue string
+
+// Inlines the given SQL as a value, never use this with user input.
+func Raw(sql string) SQLValue {
+	return rawValue(sql)
+}
+
+func (r rawValue) SQL(dialect Dialect) string {
+	return string(r)
+}
+
+type defaultValue struct{}
+
+// Uses the default value of the column. On SQLite, which has no DEFAULT
+// keyword, inserts leave out the column instead (BulkInsert.Add returns an
+// error unless the column is Default in every row).
+var Default SQLValue = defaultValue{}
+
+func (d defaultValue) SQL(dialect Dialect) string {
+	if !dialect.SupportsDefault() {
+		panic("Dialect does not support DEFAULT here")
+	}
+	return "DEFAULT"
+}
+
+func isDefault(value any) bool {
+	_, ok := value.(defaultValue)
+	return ok
+}
+
+// Renders a value: SQL values and expressions are inlined, anything else
+// becomes a placeholder
+func generateValue(value any, offset int, dialect Dialect) (string, []any) {
+	switch v := value.(type) {
+	case SQLValue:
+		return v.SQL(dialect), nil
+	case Expression:
+		return v.Generate(offset, dialect)
+	default:
+		return dialect.Placeholder(offset), []any{value}
+	}
+}
+
+// Renders a list of values as a parenthesized row
+func generateRow(values []any, offset int, dialect Dialect) (string, []any) {
+	parts := make([]string, 0)
+	vars := make([]any, 0)
+	for _, value := range values {
+		q, v := generateValue(value, offset+len(vars), dialect)
+		parts = append(parts, q)
+		vars = append(vars, v...)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, ", ")), vars
+}
@@ -22,6 +22,7 @@ const (
 	nullClause
 	notNullClause
 	arrayOverlapsClause
+	exprOpClause
 )
 
 type Where struct {
@@ -243,6 +244,46 @@ func IsNotNull(field string) Where {
 	}
 }
 
+// Returns a deep copy of the clause, which can be modified independently
+func (w Where) Clone() Where {
+	c := w
+	c.values = cloneArgs(w.values)
+	if w.children != nil {
+		c.children = make([]Where, len(w.children))
+		for i, child := range w.children {
+			c.children[i] = child.Clone()
+		}
+	}
+	if w.subQuery != nil {
+		c.subQuery = w.subQuery.Clone()
+	}
+	return c
+}
+
+// ANDs the given clauses together into a top-level clause, skipping empty ones
+func andWhere(clauses ...Where) Where {
+	w := And()
+	w.topLevel = true
+	for _, clause := range clauses {
+		if clause.IsEmpty() {
+			continue
+		}
+		if clause.mode == andClause && clause.topLevel {
+			w.children = append(w.children, clause.children...)
+		} else {
+			w.children = append(w.children, clause)
+		}
+	}
+	return w
+}
+
+func cloneArgs(args []any) []any {
+	if args == nil {
+		return nil
+	}
+	return append(make([]any, 0, len(args)), args...)
+}
+
 func (w Where) IsEmpty() bool {
 	if w.mode == andClause || w.mode == orClause {
 		isEmpty := true
@@ -264,38 +305,24 @@ func (w Where) Generate(offset int, dialect Dialect) (string, []any) {
 	case emptyClause:
 		return "", nil
 	case opClause:
-		return fmt.Sprintf("%s%s%s", w.field, w.op, dialect.Placeholder(offset)), []any{w.value}
+		q, v := generateValue(w.value, offset, dialect)
+		return fmt.Sprintf("%s%s%s", w.field, w.op, q), v
 	case andClause:
 		return w.generateCompound(offset, "AND", dialect, w.topLevel)
 	case orClause:
 		return w.generateCompound(offset, "OR", dialect, w.topLevel)
 	case inClause:
-		placeholders := make([]string, 0)
-		for range w.values {
-			placeholders = append(placeholders, dialect.Placeholder(offset+len(placeholders)))
-		}
-		return fmt.Sprintf("%s IN (%s)", w.field, strings.Join(placeholders, ", ")), w.values
+		q, v := generateRow(w.values, offset, dialect)
+		return fmt.Sprintf("%s IN %s", w.field, q), v
 	case notInClause:
-		placeholders := make([]string, 0)
-		for range w.values {
-			placeholders = append(placeholders, dialect.Placeholder(offset+len(placeholders)))
-		}
-		return fmt.Sprintf("%s NOT IN (%s)", w.field, strings.Join(placeholders, ", ")), w.values
+		q, v := generateRow(w.values, offset, dialect)
+		return fmt.Sprintf("%s NOT IN %s", w.field, q), v
 	case likeClause:
 		return fmt.Sprintf("%s LIKE %s", w.field, dialect.Placeholder(offset)), []any{fmt.Sprintf("%%%s%%", w.value)}
 	case ilikeClause:
 		return fmt.Sprintf("%s ILIKE %s", w.field, dialect.Placeholder(offset)), []any{fmt.Sprintf("%%%s%%", w.value)}
 	case exprClause:
-		placeholders := offset
-		expr := placeholderRe.ReplaceAllStringFunc(w.field, func(match string) string {
-			if match == "??" {
-				return "?"
-			}
-			s := dialect.Placeholder(placeholders)
-			placeholders += 1
-			return s
-		})
-		return expr, w.values
+		return replacePlaceholders(w.field, w.values, offset, dialect)
 	case nullClause:
 		return fmt.Sprintf("%s IS NULL", w.field), []any{}
 	case notNullClause:
@@ -307,6 +334,10 @@ func (w Where) Generate(offset int, dialect Dialect) (string, []any) {
 		}
 		q, args := w.subQuery.toSQL(offset)
 		return fmt.Sprintf("%s%s (%s)", f, w.op, q), args
+	case exprOpClause:
+		left, leftVars := w.values[0].(Expression).Generate(offset, dialect)
+		right, rightVars := generateValue(w.value, offset+len(leftVars), dialect)
+		return fmt.Sprintf("%s%s%s", left, w.op, right), append(leftVars, rightVars...)
 	case arrayOverlapsClause:
 		return fmt.Sprintf("%s %s (%s)", w.field, w.op, dialect.Placeholder(offset)), w.values
 	default:
@@ -314,6 +345,64 @@ func (w Where) Generate(offset int, dialect Dialect) (string, []any) {
 	}
 }
 
+// Replaces each ? in expr by a dialect placeholder, ?? escapes a literal ?
+func countPlaceholders(expr string) int {
+	n := 0
+	for _, match := range placeholderRe.FindAllString(expr, -1) {
+		if match != "??" {
+			n++
+		}
+	}
+	return n
+}
+
+// Numbers the placeholders of raw SQL for the dialect. Arguments that are SQL
+// values (e.g. Now()) are inlined and left out of the returned arguments.
+func replacePlaceholders(expr string, args []any, offset int, dialect Dialect) (string, []any) {
+	if !hasSQLValue(args) {
+		return placeholderRe.ReplaceAllStringFunc(expr, func(match string) string {
+			if match == "??" {
+				return "?"
+			}
+			s := dialect.Placeholder(offset)
+			offset += 1
+			return s
+		}), args
+	}
+
+	vars := make([]any, 0, len(args))
+	n := 0
+	q := placeholderRe.ReplaceAllStringFunc(expr, func(match string) string {
+		if match == "??" {
+			return "?"
+		}
+		if n < len(args) {
+			if v, ok := args[n].(SQLValue); ok {
+				n++
+				return v.SQL(dialect)
+			}
+			vars = append(vars, args[n])
+		}
+		n++
+		s := dialect.Placeholder(offset)
+		offset += 1
+		return s
+	})
+	if n < len(args) {
+		vars = append(vars, args[n:]...)
+	}
+	return q, vars
+}
+
+func hasSQLValue(args []any) bool {
+	for _, arg := range args {
+		if _, ok := arg.(SQLValue); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (w Where) generateCompound(offset int, verb string, dialect Dialect, topLevel bool) (string, []any) {
 	parts := make([]string, 0)
 	vars := make([]any, 0)
@@ -189,3 +189,22 @@ func TestArrayOverlapsValues(t *testing.T) {
 	assert.Equal(1, len(v))
 	assert.Equal([]string{"foo", "bar"}, v[0])
 }
+
+func TestWhereClone(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	w := And(FieldEquals("a", 1), Or(FieldEquals("b", 2), FieldIn("c", []any{3, 4})))
+	c := w.Clone()
+	c.children[1].children = append(c.children[1].children, FieldEquals("d", 5))
+	c.children[1].children[1].values[0] = 6
+
+	s, v := w.Generate(0, MySQLDialect{})
+	assert.Equal("(a=? AND (b=? OR c IN (?, ?)))", s)
+	assert.Equal([]any{1, 2, 3, 4}, v)
+
+	s, v = c.Generate(0, MySQLDialect{})
+	assert.Equal("(a=? AND (b=? OR c IN (?, ?) OR d=?))", s)
+	assert.Equal([]any{1, 2, 6, 4, 5}, v)
+}
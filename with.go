@@ -3,6 +3,34 @@ package query
 type InsertUpdateOptions struct {
 	CopyAutoIncrement bool
 	CopyReadOnly      bool
+
+	// Only copies these columns (the timestamp and version columns are
+	// always copied)
+	Only []string
+
+	// Never copies these columns
+	Exclude []string
+
+	// Set by WithChanges: the Only columns changed, so they're copied even
+	// when they're empty
+	changes bool
+}
+
+func (o *InsertUpdateOptions) skip(f structField) bool {
+	for _, column := range o.Exclude {
+		if column == f.column {
+			return true
+		}
+	}
+	if len(o.Only) == 0 || f.hasOption("autocreate") || f.hasOption("autoupdate") || f.hasOption("version") {
+		return false
+	}
+	for _, column := range o.Only {
+		if column == f.column {
+			return false
+		}
+	}
+	return true
 }
 
 type WithOpt func(o *InsertUpdateOptions)
@@ -18,3 +46,15 @@ func WithReadOnly() WithOpt {
 		o.CopyReadOnly = true
 	}
 }
+
+func WithOnly(columns ...string) WithOpt {
+	return func(o *InsertUpdateOptions) {
+		o.Only = append(o.Only, columns...)
+	}
+}
+
+func WithExclude(columns ...string) WithOpt {
+	return func(o *InsertUpdateOptions) {
+		o.Exclude = append(o.Exclude, columns...)
+	}
+}